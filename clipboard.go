@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// errNoClipboard is returned when no clipboard tool can reach a desktop session
+var errNoClipboard = errors.New("no clipboard available, on Linux a desktop session with wl-clipboard (Wayland) or xclip (X11) is required")
+
+// copyToClipboard places png image on the system clipboard using the clipboard tool of the platform
+func copyToClipboard(png []byte) error {
+	switch runtime.GOOS {
+	case "darwin":
+		return copyFileToClipboard(png, func(path string) *exec.Cmd {
+			script := fmt.Sprintf("set the clipboard to (read (POSIX file %q) as «class PNGf»)", path)
+			return exec.Command("osascript", "-e", script)
+		})
+	case "windows":
+		return copyFileToClipboard(png, func(path string) *exec.Cmd {
+			script := "Add-Type -AssemblyName System.Windows.Forms, System.Drawing; " +
+				fmt.Sprintf("[System.Windows.Forms.Clipboard]::SetImage([System.Drawing.Image]::FromFile('%s'))", strings.ReplaceAll(path, "'", "''"))
+			return exec.Command("powershell", "-NoProfile", "-STA", "-Command", script)
+		})
+	}
+
+	// Other systems use the tool of the running display server, headless systems have none
+	var cmd *exec.Cmd
+	switch {
+	case len(os.Getenv("WAYLAND_DISPLAY")) > 0 && hasCommand("wl-copy"):
+		cmd = exec.Command("wl-copy", "--type", "image/png")
+	case len(os.Getenv("DISPLAY")) > 0 && hasCommand("xclip"):
+		cmd = exec.Command("xclip", "-selection", "clipboard", "-target", "image/png")
+	default:
+		return errNoClipboard
+	}
+	// Tools keep serving the clipboard in a forked process, which would hold a pipe of captured
+	// error output open, so only the exit status is checked
+	cmd.Stdin = bytes.NewReader(png)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s failed: %v", cmd.Args[0], err)
+	}
+	return nil
+}
+
+// copyFileToClipboard writes png to temporary file for clipboard tools which read images from files
+func copyFileToClipboard(png []byte, command func(path string) *exec.Cmd) error {
+	file, err := os.CreateTemp("", "qrcode_*.png")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.Write(png); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return runClipboard(command(file.Name()))
+}
+
+// runClipboard runs clipboard tool, its output explains the failure
+func runClipboard(cmd *exec.Cmd) error {
+	output, err := cmd.CombinedOutput()
+	if err == nil {
+		return nil
+	}
+	if message := strings.TrimSpace(string(output)); len(message) > 0 {
+		return fmt.Errorf("%s failed: %s", cmd.Args[0], message)
+	}
+	return fmt.Errorf("%s failed: %v", cmd.Args[0], err)
+}
+
+// hasCommand checks whether program is found in PATH
+func hasCommand(name string) bool {
+	_, err := exec.LookPath(name)
+	return err == nil
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/mtzvd/qr-generator/qrgen"
+)
+
+// Shells supported by -completion
+var completionShells = []string{"bash", "zsh", "fish"}
+
+// completionValues returns known values of flags completed from a fixed list
+func completionValues() map[string][]string {
+	return map[string][]string{
+		"l":             append(qrgen.LevelNames(), qrgen.LevelAuto),
+		"f":             qrgen.Formats(),
+		"shape":         {qrgen.ShapeSquare, qrgen.ShapeCircle},
+		"eyestyle":      {qrgen.EyeSquare, qrgen.EyeRounded},
+		"console":       consoleStyles,
+		"console-style": consoleStyles,
+		"name-from":     nameSources,
+		"compress":      {qrgen.CompressDeflate, qrgen.CompressNone},
+		"png-level":     {qrgen.PNGSpeed, qrgen.PNGDefault, qrgen.PNGBest},
+		"auth":          {"WPA", "WEP", "nopass"},
+		"completion":    completionShells,
+	}
+}
+
+// Flags taking a file path, completed with file names
+var completionFileFlags = map[string]bool{
+	"i":        true,
+	"csv":      true,
+	"file":     true,
+	"decode":   true,
+	"logo":     true,
+	"bg-image": true,
+	"config":   true,
+	"d":        true,
+}
+
+// isBoolFlag checks whether flag takes no value
+func isBoolFlag(f *flag.Flag) bool {
+	b, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
+
+// writeCompletion writes completion script of shell for all flags of fs, the script is loaded with
+// source
+func writeCompletion(w io.Writer, fs *flag.FlagSet, shell string) error {
+	program := fs.Name()
+	values := completionValues()
+
+	switch shell {
+	case "bash":
+		function := "_" + strings.NewReplacer("-", "_", ".", "_").Replace(program) + "_completion"
+		var names []string
+		var cases strings.Builder
+		fs.VisitAll(func(f *flag.Flag) {
+			names = append(names, "-"+f.Name)
+			if list, ok := values[f.Name]; ok {
+				fmt.Fprintf(&cases, "    -%s)\n        COMPREPLY=($(compgen -W \"%s\" -- \"$cur\"))\n        return\n        ;;\n", f.Name, strings.Join(list, " "))
+			} else if completionFileFlags[f.Name] {
+				fmt.Fprintf(&cases, "    -%s)\n        COMPREPLY=($(compgen -f -- \"$cur\"))\n        return\n        ;;\n", f.Name)
+			}
+		})
+		fmt.Fprintf(w, "# bash completion of %s, load with: source <(%s -completion bash)\n", program, program)
+		fmt.Fprintf(w, "%s() {\n    local cur=\"${COMP_WORDS[COMP_CWORD]}\"\n    local prev=\"${COMP_WORDS[COMP_CWORD-1]}\"\n", function)
+		fmt.Fprintf(w, "    case \"$prev\" in\n%s    esac\n", cases.String())
+		fmt.Fprintf(w, "    COMPREPLY=($(compgen -W \"%s\" -- \"$cur\"))\n}\n", strings.Join(names, " "))
+		fmt.Fprintf(w, "complete -o default -F %s %s\n", function, program)
+	case "zsh":
+		function := "_" + strings.NewReplacer("-", "_", ".", "_").Replace(program)
+		fmt.Fprintf(w, "#compdef %s\n# zsh completion of %s, load with: source <(%s -completion zsh)\n", program, program, program)
+		fmt.Fprintf(w, "%s() {\n    _arguments \\\n", function)
+		fs.VisitAll(func(f *flag.Flag) {
+			spec := fmt.Sprintf("-%s[%s]", f.Name, zshEscape(completionDescription(f)))
+			switch {
+			case isBoolFlag(f):
+			case values[f.Name] != nil:
+				spec += fmt.Sprintf(":%s:(%s)", f.Name, strings.Join(values[f.Name], " "))
+			case completionFileFlags[f.Name]:
+				spec += fmt.Sprintf(":%s:_files", f.Name)
+			default:
+				spec += fmt.Sprintf(":%s:", f.Name)
+			}
+			fmt.Fprintf(w, "        %s \\\n", shellQuote(spec))
+		})
+		fmt.Fprintf(w, "        && return 0\n}\ncompdef %s %s\n", function, program)
+	case "fish":
+		fmt.Fprintf(w, "# fish completion of %s, load with: %s -completion fish | source\n", program, program)
+		fs.VisitAll(func(f *flag.Flag) {
+			line := fmt.Sprintf("complete -c %s -o %s -d %s", program, f.Name, shellQuote(completionDescription(f)))
+			switch {
+			case isBoolFlag(f):
+			case values[f.Name] != nil:
+				line += fmt.Sprintf(" -x -a %s", shellQuote(strings.Join(values[f.Name], " ")))
+			case completionFileFlags[f.Name]:
+				line += " -r -F"
+			default:
+				line += " -x"
+			}
+			fmt.Fprintln(w, line)
+		})
+	default:
+		return fmt.Errorf("unsupported shell '%s', choose from %s", shell, strings.Join(completionShells, ", "))
+	}
+
+	return nil
+}
+
+// completionDescription returns first line of flag usage
+func completionDescription(f *flag.Flag) string {
+	_, usage := flag.UnquoteUsage(f)
+	description, _, _ := strings.Cut(usage, "\n")
+	return description
+}
+
+// zshEscape escapes brackets and colons which delimit zsh _arguments specs
+func zshEscape(text string) string {
+	return strings.NewReplacer("[", "\\[", "]", "\\]", ":", "\\:").Replace(text)
+}
+
+// shellQuote quotes text in single quotes for bash, zsh and fish
+func shellQuote(text string) string {
+	return "'" + strings.ReplaceAll(text, "'", "'\\''") + "'"
+}
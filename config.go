@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// configFlagName is the flag which loads the config file, it cannot be set from the file itself
+const configFlagName = "config"
+
+// Environment variables holding flag defaults
+var envFlags = []struct{ env, flag string }{
+	{"QRGEN_SIZE", "s"},
+	{"QRGEN_LEVEL", "l"},
+	{"QRGEN_FORMAT", "f"},
+	{"QRGEN_DIR", "d"},
+}
+
+// Source of flags given on the command line
+const sourceCommandLine = "command line"
+
+// flagSources maps names of flags given on the command line to sourceCommandLine
+func flagSources(fs *flag.FlagSet) map[string]string {
+	sources := make(map[string]string)
+	fs.Visit(func(f *flag.Flag) {
+		sources[f.Name] = sourceCommandLine
+	})
+	return sources
+}
+
+// hasCommandLineFlags reports whether any flag in sources was given on the command line
+func hasCommandLineFlags(sources map[string]string) bool {
+	for _, source := range sources {
+		if source == sourceCommandLine {
+			return true
+		}
+	}
+	return false
+}
+
+// applyEnv sets flags without a value in sources from non-empty environment variables of envFlags
+// and records the variable as their source
+func applyEnv(fs *flag.FlagSet, getenv func(string) string, sources map[string]string) error {
+	for _, env := range envFlags {
+		value := getenv(env.env)
+		if len(value) == 0 || len(sources[env.flag]) > 0 {
+			continue
+		}
+		if err := fs.Set(env.flag, value); err != nil {
+			return fmt.Errorf("invalid value '%s' of environment variable %s", value, env.env)
+		}
+		sources[env.flag] = "environment variable " + env.env
+	}
+	return nil
+}
+
+// applyConfig reads JSON object of flag names and values from path and sets every flag without a
+// value in sources, so the config file goes through the same parsing and validation
+func applyConfig(fs *flag.FlagSet, path string, sources map[string]string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var values map[string]any
+	if err := json.Unmarshal(data, &values); err != nil {
+		return fmt.Errorf("cannot parse config file '%s': %v", path, err)
+	}
+
+	// Sorted, so the first invalid option is reported consistently
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		value := values[name]
+		if name == configFlagName || fs.Lookup(name) == nil {
+			return fmt.Errorf("unknown option '%s' in config file '%s'", name, path)
+		}
+		// Command line and environment override the file
+		if len(sources[name]) > 0 {
+			continue
+		}
+		text, err := configValue(value)
+		if err != nil {
+			return fmt.Errorf("option '%s' in config file '%s': %v", name, path, err)
+		}
+		if err := fs.Set(name, text); err != nil {
+			return fmt.Errorf("invalid value '%s' of option '%s' in config file '%s'", text, name, path)
+		}
+		sources[name] = fmt.Sprintf("config file '%s'", path)
+	}
+
+	return nil
+}
+
+// configValue converts JSON value to flag value, arrays become comma-separated lists
+func configValue(value any) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case []any:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			text, ok := item.(string)
+			if !ok {
+				return "", fmt.Errorf("list items must be strings")
+			}
+			items = append(items, text)
+		}
+		return strings.Join(items, ","), nil
+	default:
+		return "", fmt.Errorf("value must be a string, number, boolean or list of strings")
+	}
+}
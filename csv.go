@@ -0,0 +1,131 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/mtzvd/qr-generator/qrgen"
+)
+
+// Columns of CSV batch file, only url is required
+var csvColumns = []string{"url", "filename", "size", "level", "format"}
+
+// readCSVEntries reads batch entries from CSV file with header row. Non-empty cells override opts
+// and formats, rows with invalid settings are returned with err set, so they are reported and
+// skipped while the rest of the batch is generated.
+func readCSVEntries(path string, opts qrgen.Options, formats []string, sizes []int) ([]batchEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("CSV file '%s' is empty", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot read CSV file '%s': %v", path, err)
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !isCSVColumn(name) {
+			return nil, fmt.Errorf("unknown column '%s' in CSV file '%s', supported columns are %s", name, path, strings.Join(csvColumns, ", "))
+		}
+		columns[name] = i
+	}
+	if _, ok := columns["url"]; !ok {
+		return nil, fmt.Errorf("CSV file '%s' has no url column", path)
+	}
+
+	var entries []batchEntry
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cannot read CSV file '%s': %v", path, err)
+		}
+		if len(strings.TrimSpace(strings.Join(record, ""))) == 0 {
+			continue
+		}
+		line, _ := reader.FieldPos(0)
+
+		cell := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		entry := batchEntry{url: cell("url"), line: line, filename: cell("filename"), opts: opts, formats: formats, sizes: sizes}
+		entry.err = applyCSVRow(&entry, cell)
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// applyCSVRow applies non-empty size, level and format cells, which can be lists like the flags, to entry and validates the result
+func applyCSVRow(entry *batchEntry, cell func(string) string) error {
+	if len(entry.url) == 0 {
+		return fmt.Errorf("url is empty")
+	}
+	if size := cell("size"); len(size) > 0 {
+		sizes, err := qrgen.ParseSizeList(size)
+		if err != nil {
+			return err
+		}
+		entry.sizes, entry.opts.Size = sizes, sizes[0]
+	}
+	if level := cell("level"); len(level) > 0 {
+		entry.opts.AutoLevel = level == qrgen.LevelAuto
+		if !entry.opts.AutoLevel {
+			value, err := qrgen.ParseLevel(level)
+			if err != nil {
+				return err
+			}
+			entry.opts.Level = value
+		}
+	}
+	if format := cell("format"); len(format) > 0 {
+		formats, err := qrgen.ParseFormatList(format)
+		if err != nil {
+			return err
+		}
+		entry.formats = formats
+	}
+
+	opts := entry.opts
+	for _, size := range entry.sizes {
+		for _, format := range entry.formats {
+			opts.Size, opts.Format = size, format
+			if err := opts.Validate(); err != nil {
+				return err
+			}
+		}
+	}
+	entry.opts.Format = entry.formats[0]
+	return nil
+}
+
+// isCSVColumn checks whether name is one of csvColumns
+func isCSVColumn(name string) bool {
+	for _, column := range csvColumns {
+		if column == name {
+			return true
+		}
+	}
+	return false
+}
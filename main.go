@@ -1,14 +1,30 @@
 package main
 
 import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"log"
+	"net/url"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"regexp"
+	"runtime"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
+	"unicode/utf8"
 
+	"github.com/mtzvd/qr-generator/qrgen"
 	"github.com/skip2/go-qrcode"
 )
 
@@ -16,166 +32,1768 @@ import (
 const (
 	errCodeGeneralFailure        = 1
 	errCodeCommandLineUsageError = 2
+	errCodeEncodingFailure       = 3
+	errCodeWriteFailure          = 4
+	errCodeInterrupted           = 130 // 128 + SIGINT by shell convention
 	maxURLLength                 = 2048
-	minQRSize                    = 100
-	maxQRSize                    = 4096
-	unitSize                     = 6
+	stdoutFilename               = "-"
+	defaultTimestampFormat       = "20060102150405"
 )
 
-// List of supported output file formats
-var supportedFormats = map[string]bool{
-	"png": true,
-	"svg": true,
+// Debug logger enabled with -v, discards messages by default
+var verboseLog = log.New(io.Discard, "", log.Ltime|log.Lmicroseconds)
+
+// Styles of console preview
+var consoleStyles = []string{"small", "halfblock", "full", "ascii"}
+
+// sanitizeFilename cleans generated and user supplied filenames, -ascii-names keeps ASCII only
+var sanitizeFilename = qrgen.SanitizeFilename
+
+// cliOptions Command line settings shared by single and batch modes, which are not part of generation
+type cliOptions struct {
+	dir       string
+	strict    bool
+	force     bool
+	increment bool
+	info      bool
+	tsFormat  string
+	// nameFrom selects payload or URL host as base of auto-generated filenames, noHash omits
+	// payload hash from them
+	nameFrom string
+	noHash   bool
+	// deterministic names files by hash of payload and settings instead of time, images are the
+	// logo and background image paths included in the hash
+	deterministic bool
+	images        string
+	json          bool
+	jobs          int
+	timeout       time.Duration
+	dryRun        bool
+	verify        bool
+	// retries is the number of repeated attempts of file write failed with transient error
+	retries int
+	// query holds parameters merged into every URL
+	query url.Values
+	// nameTemplate replaces auto-generated filenames when set
+	nameTemplate string
+	stdout       io.Writer
+	stderr       io.Writer
+	// notices receives status messages and progress, io.Discard with -quiet
+	notices io.Writer
+}
+
+// batchEntry One QR code of the batch with its own generation settings
+type batchEntry struct {
+	url string
+	// line is the line of CSV row, zero for URL lists
+	line int
+	// filename is the output filename, auto-generated when empty
+	filename string
+	opts     qrgen.Options
+	formats  []string
+	sizes    []int
+	// err marks invalid row which is reported and skipped
+	err error
+}
+
+// batchEntries creates entries of URLs sharing generation settings
+func batchEntries(urls []string, opts qrgen.Options, formats []string, sizes []int) []batchEntry {
+	entries := make([]batchEntry, len(urls))
+	for i, url := range urls {
+		entries[i] = batchEntry{url: url, opts: opts, formats: formats, sizes: sizes}
+	}
+	return entries
+}
+
+// batchItem Outcome of one URL of the batch, collected by index to keep input order, skipped
+// items were not started before interruption
+type batchItem struct {
+	index   int
+	written []generationSummary
+	err     error
+	skipped bool
 }
 
-// Store regular expression for reuse
-var filenameSanitizer = regexp.MustCompile(`[^a-zA-Z0-9]+`)
+// generationSummary Machine-readable result of one QR code generation printed with -json
+type generationSummary struct {
+	Input         string `json:"input,omitempty"`
+	Path          string `json:"path,omitempty"`
+	Format        string `json:"format"`
+	Size          int    `json:"size"`
+	Level         string `json:"level,omitempty"`
+	Version       int    `json:"version,omitempty"`
+	PayloadLength int    `json:"payload_length"`
+	BytesWritten  int    `json:"bytes_written"`
+	DryRun        bool   `json:"dry_run,omitempty"`
+	Error         string `json:"error,omitempty"`
+}
 
-// sValidFormat Helper function which checks wether specified format is in supported formats.
-func isValidFormat(format string) bool {
-	_, ok := supportedFormats[format]
-	return ok
+// decodeSummary Decoded content of QR code image printed with -json
+type decodeSummary struct {
+	Input   string `json:"input"`
+	Content string `json:"content"`
 }
 
-// exitOnError Helper function to check and exit on errors
-func exitOnError(err error) {
+// reportError Helper function to print error and return exit code of its failure class
+func reportError(stderr io.Writer, err error, code int) int {
+	fmt.Fprintf(stderr, "Error: %v\n", err)
+	return code
+}
+
+// Flags left out of usage message
+var hiddenFlags = map[string]bool{
+	"completion": true,
+}
+
+// customUsage prints usage message of flag set with an example of every payload mode
+func customUsage(fs *flag.FlagSet, modes []payloadMode) {
+	programName := fs.Name()
+	fmt.Fprintf(fs.Output(), "Usage of %s:\n", programName)
+	fmt.Fprintf(fs.Output(), "Options:\n")
+
+	// Copy of the flag set without hidden flags prints defaults in the standard layout
+	visible := flag.NewFlagSet(programName, flag.ContinueOnError)
+	visible.SetOutput(fs.Output())
+	fs.VisitAll(func(f *flag.Flag) {
+		if !hiddenFlags[f.Name] {
+			visible.Var(f.Value, f.Name, f.Usage)
+			visible.Lookup(f.Name).DefValue = f.DefValue
+		}
+	})
+	visible.PrintDefaults()
+	fmt.Fprintf(fs.Output(), "\nExamples:\n")
+	fmt.Fprintf(fs.Output(), "  %s -u 'https://www.example.com' -s 256 -l M -f png -d /path/to/save\n", programName)
+	fmt.Fprintf(fs.Output(), "  %s -u 'https://www.example.com' -s 512 -l Q -f svg\n", programName)
+	fmt.Fprintf(fs.Output(), "  %s -u 'https://www.example.com' -fg '#1a1a1a' -bg '#f0f0f0'\n", programName)
+	fmt.Fprintf(fs.Output(), "  %s -i urls.txt -f svg -d /path/to/save\n", programName)
+	for _, mode := range modes {
+		fmt.Fprintf(fs.Output(), "  %s %s\n", programName, mode.example)
+	}
+	fmt.Fprintf(fs.Output(), "\nExit codes:\n")
+	fmt.Fprintf(fs.Output(), "  %d  success\n", 0)
+	fmt.Fprintf(fs.Output(), "  %d  general failure, e.g. unreadable input or some URLs of a batch failed\n", errCodeGeneralFailure)
+	fmt.Fprintf(fs.Output(), "  %d  invalid command line arguments\n", errCodeCommandLineUsageError)
+	fmt.Fprintf(fs.Output(), "  %d  encoding failed, e.g. content exceeds QR code capacity, -timeout expired or -verify failed\n", errCodeEncodingFailure)
+	fmt.Fprintf(fs.Output(), "  %d  output could not be written, e.g. file exists or directory is missing\n", errCodeWriteFailure)
+	fmt.Fprintf(fs.Output(), "  %d  batch interrupted by SIGINT or SIGTERM\n", errCodeInterrupted)
+}
+
+// checkURL validates payload against maximum URL length, strict mode also requires absolute URL
+// with scheme and host
+func checkURL(rawURL string, strict bool) error {
+	if len(rawURL) > maxURLLength {
+		return fmt.Errorf("URL must be less than %d characters", maxURLLength)
+	}
+	if !strict {
+		return nil
+	}
+
+	parsed, err := url.ParseRequestURI(rawURL)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(errCodeGeneralFailure)
+		return fmt.Errorf("'%s' is not a valid URL", rawURL)
+	}
+	if len(parsed.Scheme) == 0 {
+		return fmt.Errorf("URL '%s' has no scheme, e.g. https://", rawURL)
 	}
+	if len(parsed.Host) == 0 {
+		return fmt.Errorf("URL '%s' has no host", rawURL)
+	}
+	return nil
+}
+
+// queryParams Repeatable flag of key=value query parameters, one value can hold several joined by &
+type queryParams url.Values
+
+func (q queryParams) String() string {
+	return url.Values(q).Encode()
+}
+
+func (q queryParams) Set(value string) error {
+	for _, pair := range strings.Split(value, "&") {
+		key, val, ok := strings.Cut(pair, "=")
+		if !ok || len(key) == 0 {
+			return fmt.Errorf("invalid query parameter '%s', use key=value", pair)
+		}
+		key, keyErr := url.QueryUnescape(key)
+		val, valErr := url.QueryUnescape(val)
+		if keyErr != nil || valErr != nil {
+			return fmt.Errorf("invalid escape in query parameter '%s'", pair)
+		}
+		url.Values(q).Add(key, val)
+	}
+	return nil
+}
+
+// appendQuery merges params into query string of URL with scheme and host, parameters already in
+// the URL are kept as written unless params replace them, e.g. "?b=1&flag" with utm=qr becomes
+// "?b=1&flag&utm=qr". Other payloads are returned unchanged.
+func appendQuery(rawURL string, params url.Values) string {
+	if len(params) == 0 {
+		return rawURL
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil || len(parsed.Scheme) == 0 || len(parsed.Host) == 0 {
+		verboseLog.Printf("Not appending query to '%s', it is not a URL with scheme and host", rawURL)
+		return rawURL
+	}
+	var pairs []string
+	for _, pair := range strings.Split(parsed.RawQuery, "&") {
+		key, _, _ := strings.Cut(pair, "=")
+		if unescaped, err := url.QueryUnescape(key); len(pair) == 0 || (err == nil && params.Has(unescaped)) {
+			continue
+		}
+		pairs = append(pairs, pair)
+	}
+	pairs = append(pairs, params.Encode())
+	parsed.RawQuery = strings.Join(pairs, "&")
+	return parsed.String()
+}
+
+// Sources of auto-generated filenames
+const (
+	nameFromPayload = "payload"
+	nameFromHost    = "host"
+)
+
+var nameSources = []string{nameFromPayload, nameFromHost}
+
+// Longest sanitized payload or host in auto-generated filenames, in bytes, which keeps names well
+// below the 255 byte limit of common filesystems
+const maxNameStem = 100
+
+// truncateName cuts name to at most n bytes without splitting UTF-8 encoded characters
+func truncateName(name string, n int) string {
+	if len(name) <= n {
+		return name
+	}
+	for n > 0 && !utf8.RuneStart(name[n]) {
+		n--
+	}
+	return name[:n]
+}
+
+// autoFilename builds output filename from generation time formatted with tsFormat layout and payload,
+// or from host of URL payload and time with nameFrom host. Short hash of the payload keeps names of
+// payloads sanitized alike apart, e.g. a.com/x and a.com-x, unless noHash is set. Long payloads are
+// truncated, the hash covers the whole payload.
+func autoFilename(content, format, tsFormat, nameFrom string, noHash bool) string {
+	currentTime := sanitizeFilename(time.Now().Format(tsFormat))
+	name := "qrcode" + currentTime + truncateName(sanitizeFilename(content), maxNameStem)
+	if parsed, err := url.Parse(content); nameFrom == nameFromHost && err == nil && len(parsed.Hostname()) > 0 {
+		name = truncateName(sanitizeFilename(parsed.Hostname()), maxNameStem) + "_" + currentTime
+	}
+	if !noHash {
+		sum := sha256.Sum256([]byte(content))
+		name += fmt.Sprintf("_%x", sum[:3])
+	}
+	return name + "." + qrgen.Extension(format)
+}
+
+// hashFilename builds output filename from hash of the payload and generation settings, so that
+// repeated runs with the same input produce the same name
+func hashFilename(opts qrgen.Options, images, format string) string {
+	return fmt.Sprintf("qrcode_%s.%s", settingsHash(opts, images), qrgen.Extension(format))
+}
+
+// settingsHash returns hex hash of the payload and generation settings
+func settingsHash(opts qrgen.Options, images string) string {
+	// Images are identified by their paths, creation time and format do not change the code
+	opts.Logo, opts.BackgroundImage, opts.Created, opts.Format = nil, nil, time.Time{}, ""
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%+v|%s", opts, images)))
+	return fmt.Sprintf("%x", sum[:8])
 }
 
-// customUsage prints usage message
-func customUsage() {
-	programName := filepath.Base(os.Args[0]) // Get the base name of the binary
-	fmt.Fprintf(flag.CommandLine.Output(), "Usage of %s:\n", programName)
-	fmt.Fprintf(flag.CommandLine.Output(), "Options:\n")
-	flag.PrintDefaults()
-	fmt.Fprintf(flag.CommandLine.Output(), "\nExamples:\n")
-	fmt.Fprintf(flag.CommandLine.Output(), "  %s -u 'https://www.example.com' -s 256 -l M -f png -d /path/to/save\n", programName)
-	fmt.Fprintf(flag.CommandLine.Output(), "  %s -u 'https://www.example.com' -s 512 -l Q -f svg\n", programName)
+// Placeholders of -name-template, {date} is formatted with dateLayout
+var templatePlaceholders = []string{"{index}", "{date}", "{payload}", "{hash}", "{ext}"}
+
+const dateLayout = "20060102"
+
+// Matches placeholder of name template
+var placeholderPattern = regexp.MustCompile(`\{[^{}]*\}`)
+
+// checkNameTemplate rejects unknown placeholders of name template
+func checkNameTemplate(template string) error {
+	for _, placeholder := range placeholderPattern.FindAllString(template, -1) {
+		if !slices.Contains(templatePlaceholders, placeholder) {
+			return fmt.Errorf("unknown placeholder %s in -name-template, use %s", placeholder, strings.Join(templatePlaceholders, ", "))
+		}
+	}
+	return nil
 }
 
-// generateSVG generates svg vector image as string
-func generateSVG(qr *qrcode.QRCode) string {
-	var builder strings.Builder
+// templateFilename expands name template for the index-th QR code, counted from 1, and sanitizes it
+// like a user supplied filename, e.g. "{date}_{index}.{ext}" becomes "20240101_3.png". {ext} is the
+// extension of the first of formats, {payload} is truncated like in auto-generated names.
+func templateFilename(template string, index int, opts qrgen.Options, images string, formats []string) string {
+	replacer := strings.NewReplacer(
+		"{index}", strconv.Itoa(index),
+		"{date}", time.Now().Format(dateLayout),
+		"{payload}", truncateName(opts.Content, maxNameStem),
+		"{hash}", settingsHash(opts, images),
+		"{ext}", qrgen.Extension(formats[0]),
+	)
+	return buildOutputName(replacer.Replace(template), formats)
+}
 
-	bitmap := qr.Bitmap()
-	dim := len(bitmap)
+// hasFormatExtension reports whether lowercase ext is the file extension of format or its alias
+func hasFormatExtension(ext, format string) bool {
+	return ext == qrgen.Extension(format) || (format == "jpeg" && ext == "jpg") || (format == "tiff" && ext == "tif")
+}
 
-	// Use fmt.Fprintf for direct writing to builder
-	fmt.Fprintf(&builder, "<svg width=\"%d\" height=\"%d\" xmlns=\"http://www.w3.org/2000/svg\">\n", dim*unitSize, dim*unitSize)
-	for y := 0; y < dim; y++ {
-		for x := 0; x < dim; x++ {
-			if bitmap[y][x] {
-				fmt.Fprintf(&builder, "<rect x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\" fill=\"#000\"/>\n", x*unitSize, y*unitSize, unitSize, unitSize)
+// buildOutputName sanitizes user supplied filename for the first of formats, the extension of any of
+// formats is stripped and the one of the first appended, e.g. "my.file.png" stays "my_file.png",
+// "my.file" becomes "my_file.png" and "logo.png" of svg and png becomes "logo.svg". The extension is
+// kept as written when it is already the one of the first format, e.g. "photo.JPG".
+func buildOutputName(userName string, formats []string) string {
+	if i := strings.LastIndex(userName, "."); i > 0 {
+		ext := strings.ToLower(userName[i+1:])
+		if hasFormatExtension(ext, formats[0]) {
+			return sanitizeFilename(userName[:i]) + userName[i:]
+		}
+		for _, format := range formats[1:] {
+			if hasFormatExtension(ext, format) {
+				return sanitizeFilename(userName[:i]) + "." + qrgen.Extension(formats[0])
 			}
 		}
 	}
-	builder.WriteString("</svg>")
+	return sanitizeFilename(userName) + "." + qrgen.Extension(formats[0])
+}
 
-	return builder.String()
+// printFormats prints registered output formats and their file extensions separated by tab
+func printFormats(w io.Writer) {
+	for _, format := range qrgen.Formats() {
+		fmt.Fprintf(w, "%s\t%s\n", format, qrgen.Extension(format))
+	}
 }
 
-// sanitizeFilename clears string from characters unsafe for filenames
-func sanitizeFilename(input string) string {
-	return filenameSanitizer.ReplaceAllString(input, "_")
+// printInfo prints QR code version, correction level, dimension and payload length
+func printInfo(w io.Writer, qr *qrcode.QRCode, border int) {
+	dim := qrgen.SymbolSize(qr)
+	fmt.Fprintf(w, "QR version: %d\n", qr.VersionNumber)
+	fmt.Fprintf(w, "Correction level: %s\n", qrgen.LevelName(qr.Level))
+	fmt.Fprintf(w, "Modules: %dx%d (%dx%d with quiet zone)\n", dim, dim, dim+2*border, dim+2*border)
+	fmt.Fprintf(w, "Payload: %d bytes\n", len(qr.Content))
 }
 
-func main() {
+// printCapacity prints payload mode and length with capacity of its QR version at every level
+func printCapacity(w io.Writer, capacity qrgen.Capacity, length int) {
+	fmt.Fprintf(w, "Payload: %d bytes, %s mode\n", length, capacity.Mode)
+	if capacity.Fits {
+		fmt.Fprintf(w, "QR version: %d\n", capacity.Version)
+	} else {
+		fmt.Fprintf(w, "QR version: %d, payload does not fit at the selected level\n", capacity.Version)
+	}
+	for i, name := range qrgen.LevelNames() {
+		fmt.Fprintf(w, "Capacity at level %s: %d characters\n", name, capacity.Levels[i])
+	}
+}
+
+// newSummary describes written QR code
+func newSummary(result qrgen.Result, opts qrgen.Options, path string) generationSummary {
+	size := opts.Size
+	if opts.Scale > 0 {
+		size = qrgen.ScaledSize(result.QRCode, opts.Scale, opts.Border)
+	}
+	return generationSummary{
+		Path:          path,
+		Format:        opts.Format,
+		Size:          size,
+		Level:         qrgen.LevelName(result.QRCode.Level),
+		Version:       result.QRCode.VersionNumber,
+		PayloadLength: len(opts.Content),
+		BytesWritten:  len(result.Data),
+	}
+}
+
+// printJSON prints value as indented JSON
+func printJSON(w io.Writer, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
+
+// formatPaths derives path for every format from the path of the first format by replacing extension
+func formatPaths(path string, formats []string) []string {
+	base := strings.TrimSuffix(path, filepath.Ext(path))
+	paths := []string{path}
+	for _, format := range formats[1:] {
+		paths = append(paths, base+"."+qrgen.Extension(format))
+	}
+	return paths
+}
+
+// outputPaths derives path of every size and format from the path of the first format, results of
+// GenerateSizes are in the same order. Several sizes get the size appended to the name, e.g. name@512.png.
+func outputPaths(path string, formats []string, sizes []int) []string {
+	if len(sizes) == 1 {
+		return formatPaths(path, formats)
+	}
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(path, ext)
+	paths := make([]string, 0, len(sizes)*len(formats))
+	for _, size := range sizes {
+		paths = append(paths, formatPaths(fmt.Sprintf("%s@%d%s", base, size, ext), formats)...)
+	}
+	return paths
+}
+
+// writeResults saves QR code rendered in every size and format, paths are adjusted with -increment.
+// Dry run only describes the files.
+func writeResults(results []qrgen.Result, opts qrgen.Options, formats []string, sizes []int, paths []string, cli cliOptions) ([]generationSummary, error) {
+	summaries := make([]generationSummary, 0, len(results))
+	for i, result := range results {
+		path := paths[i]
+		special := isSpecialFile(path)
+		if cli.increment && !special {
+			path = uniquePath(path)
+		}
+
+		opts.Format, opts.Size = formats[i%len(formats)], sizes[i/len(formats)]
+		summary := newSummary(result, opts, path)
+		if cli.dryRun {
+			verboseLog.Printf("Dry run, skipping write of %d bytes to %s", len(result.Data), path)
+			summary.BytesWritten, summary.DryRun = 0, true
+			summaries = append(summaries, summary)
+			continue
+		}
+
+		verboseLog.Printf("Writing %d bytes to %s", len(result.Data), path)
+		var err error
+		if special {
+			err = writeSpecialFile(path, result)
+		} else {
+			err = writeWithRetry(path, result, cli.retries)
+		}
+		if err != nil {
+			return summaries, err
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries, nil
+}
+
+// printSaved prints paths of written files to notices, dry run reports where they would be written
+func printSaved(cli cliOptions, summaries []generationSummary) {
+	if cli.dryRun {
+		fmt.Fprintf(cli.notices, "QR code version %d would be saved as: %s\n", summaries[0].Version, summaryPaths(summaries))
+		return
+	}
+	fmt.Fprintln(cli.notices, "QR code saved as:", summaryPaths(summaries))
+}
+
+// writeFileAtomic writes content to temporary file in the directory of path and renames it into
+// place, so readers see either the complete file or none
+func writeFileAtomic(path string, content io.WriterTo) error {
+	file, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	// Removing fails harmlessly once the file is renamed
+	defer os.Remove(file.Name())
+
+	if _, err := content.WriteTo(file); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	// Temporary files are created private, output keeps the usual permissions
+	if err := os.Chmod(file.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
+
+// Delay before the first repeated write, doubled by every further attempt
+const retryBackoff = 100 * time.Millisecond
+
+// writeWithRetry writes file atomically and repeats the write up to retries times while it fails
+// with transient error, e.g. EAGAIN of network filesystem. Other errors fail at once.
+func writeWithRetry(path string, content io.WriterTo, retries int) error {
+	delay := retryBackoff
+	for attempt := 0; ; attempt++ {
+		err := writeFileAtomic(path, content)
+		if err == nil || attempt == retries || !isTransient(err) {
+			return err
+		}
+		verboseLog.Printf("Writing %s failed: %v, retrying in %s", path, err, delay)
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// isTransient reports whether error is temporary system error which may succeed on retry,
+// permission and disk space errors are not
+func isTransient(err error) bool {
+	var temporary interface{ Temporary() bool }
+	return errors.As(err, &temporary) && temporary.Temporary()
+}
+
+// isSpecialFile reports whether path exists and is neither regular file nor directory, e.g. named
+// pipe or device
+func isSpecialFile(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.Mode().IsRegular() && !info.IsDir()
+}
+
+// writeSpecialFile streams content into existing pipe or device, which cannot be replaced by rename
+// like regular files and has no partial state to retry from
+func writeSpecialFile(path string, content io.WriterTo) error {
+	file, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	if _, err := content.WriteTo(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// verifyResults decodes QR code rendered in every size and format and checks that it holds the payload
+func verifyResults(results []qrgen.Result, opts qrgen.Options, formats []string, sizes []int) error {
+	for i, result := range results {
+		opts.Format, opts.Size = formats[i%len(formats)], sizes[i/len(formats)]
+		decoded, err := qrgen.Verify(result, opts)
+		if err != nil {
+			return fmt.Errorf("verification of %s output failed: %w", opts.Format, err)
+		}
+		verboseLog.Printf("Verified %s output, decoded %q", opts.Format, decoded)
+	}
+	return nil
+}
+
+// summaryPaths lists paths of written files for messages
+func summaryPaths(summaries []generationSummary) string {
+	paths := make([]string, 0, len(summaries))
+	for _, summary := range summaries {
+		paths = append(paths, summary.Path)
+	}
+	return strings.Join(paths, ", ")
+}
+
+// withTimeout limits generation to timeout, zero timeout means no limit
+func withTimeout(parent context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout == 0 {
+		return context.WithCancel(parent)
+	}
+	return context.WithTimeout(parent, timeout)
+}
+
+// checkOverwrite refuses to overwrite existing file unless force is set
+func checkOverwrite(path string, force bool) error {
+	// Pipes and devices are written into, not replaced
+	if force || isSpecialFile(path) {
+		return nil
+	}
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("file '%s' already exists, use -force to overwrite it", path)
+	}
+	return nil
+}
+
+// uniquePath appends counter to the filename until path does not exist, e.g. name_1.png
+func uniquePath(path string) string {
+	if _, err := os.Stat(path); err != nil {
+		return path
+	}
+
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(path, ext)
+	for i := 1; ; i++ {
+		candidate := fmt.Sprintf("%s_%d%s", base, i, ext)
+		if _, err := os.Stat(candidate); err != nil {
+			return candidate
+		}
+	}
+}
+
+// prepareDir checks that output directory exists, creating it when mkdir is set
+func prepareDir(dir string, mkdir bool) error {
+	if mkdir {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("cannot create output directory '%s': %v", dir, errors.Unwrap(err))
+		}
+		return nil
+	}
+
+	info, err := os.Stat(dir)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("output directory '%s' does not exist, use -mkdir to create it", dir)
+	}
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("output path '%s' is not a directory", dir)
+	}
+	return nil
+}
+
+// decodeQR reads QR code image from path, '-' reads it from stdin, and prints its content.
+// Returns exit code.
+func decodeQR(path string, jsonOutput bool, stdout, stderr io.Writer) int {
+	var data []byte
+	var err error
+	if path == stdoutFilename {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return reportError(stderr, err, errCodeGeneralFailure)
+	}
+
+	content, err := qrgen.Decode(data)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: cannot decode '%s': %v.\n", path, err)
+		return errCodeGeneralFailure
+	}
+	verboseLog.Printf("Decoded %d bytes from %s", len(content), path)
+
+	if jsonOutput {
+		if err := printJSON(stdout, decodeSummary{Input: path, Content: content}); err != nil {
+			return reportError(stderr, err, errCodeGeneralFailure)
+		}
+		return 0
+	}
+	fmt.Fprintln(stdout, content)
+	return 0
+}
+
+// readPayloadFile reads file contents to encode, files larger than the largest QR code are rejected
+// before reading
+func readPayloadFile(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if info.Size() > qrgen.MaxBinaryBytes {
+		return "", fmt.Errorf("file '%s' has %d bytes, QR code holds at most %d bytes", path, info.Size(), qrgen.MaxBinaryBytes)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	if len(data) == 0 {
+		return "", fmt.Errorf("file '%s' is empty", path)
+	}
+	return string(data), nil
+}
+
+// eventPayload parses event times and builds VEVENT payload, end time is optional
+func eventPayload(title, start, end, location string) (string, error) {
+	var startTime, endTime time.Time
+	var err error
+	if len(start) > 0 {
+		if startTime, err = qrgen.ParseEventTime(start); err != nil {
+			return "", fmt.Errorf("-event-start: %w", err)
+		}
+	}
+	if len(end) > 0 {
+		if endTime, err = qrgen.ParseEventTime(end); err != nil {
+			return "", fmt.Errorf("-event-end: %w", err)
+		}
+	}
+	return qrgen.EventPayload(title, location, startTime, endTime)
+}
+
+// readURLList reads file with one URL per line, blank lines are skipped
+func readURLList(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return readURLs(file)
+}
+
+// readURLs reads one URL per line, surrounding whitespace and line endings are stripped
+func readURLs(r io.Reader) ([]string, error) {
+	var urls []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) > 0 {
+			urls = append(urls, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return urls, nil
+}
+
+// stdinIsPiped reports whether stdin is redirected from a pipe or file instead of a terminal
+func stdinIsPiped() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice == 0
+}
+
+// contrastRatio returns lowest contrast ratio of module colors against the background, ok is false
+// for transparent background
+func contrastRatio(opts qrgen.Options) (ratio float64, ok bool) {
+	if opts.Background == qrgen.TransparentColor {
+		return 0, false
+	}
+	bg, _ := qrgen.ParseHexColor(opts.Background)
+	fg, _ := qrgen.ParseHexColor(opts.Foreground)
+	if len(opts.Gradient) == 0 {
+		return qrgen.ContrastRatio(fg, bg), true
+	}
+	gradient, _ := qrgen.ParseGradient(opts.Gradient)
+	return min(qrgen.ContrastRatio(gradient.From, bg), qrgen.ContrastRatio(gradient.To, bg)), true
+}
+
+// isFlagSet reports whether flag has a value from any source in sources, i.e. the command line, an
+// environment variable or a config file. Conflicts between flags compare sources[name] with
+// sourceCommandLine instead, so that defaults never conflict.
+func isFlagSet(sources map[string]string, name string) bool {
+	return len(sources[name]) > 0
+}
+
+// isTerminal Helper function to check if output is connected to terminal
+func isTerminal(w io.Writer) bool {
+	file, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// generateBatch generates QR code for every entry, failures are collected and reported at the end.
+// When ctx is done, workers finish the entries in flight and skip the rest. Returns exit code.
+func generateBatch(ctx context.Context, entries []batchEntry, cli cliOptions) int {
+	jobs := make(chan int, len(entries))
+	for i := range entries {
+		jobs <- i
+	}
+	close(jobs)
+
+	// Workers send outcomes over channel, only this goroutine touches the collected items
+	results := make(chan batchItem)
+	var writeMu sync.Mutex
+	var wg sync.WaitGroup
+	for w := 0; w < min(cli.jobs, len(entries)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if ctx.Err() != nil {
+					results <- batchItem{index: i, skipped: true}
+					continue
+				}
+				written, err := generateBatchItem(entries[i], i+1, cli, &writeMu)
+				results <- batchItem{index: i, written: written, err: err}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	// Progress is updated in place, so it is only shown on terminal without other stderr output
+	progress := isTerminal(cli.notices) && !cli.json && !cli.info && verboseLog.Writer() == io.Discard
+	items := make([]batchItem, len(entries))
+	done := 0
+	for item := range results {
+		items[item.index] = item
+		done++
+		if progress {
+			fmt.Fprintf(cli.notices, "\rGenerating %d/%d", done, len(entries))
+		}
+	}
+	if progress {
+		// Clear the progress line before the summary
+		fmt.Fprintf(cli.notices, "\r\033[K")
+	}
+
+	var failures []string
+	skipped := 0
+	summaries := make([]generationSummary, 0, len(entries))
+
+	for i, entry := range entries {
+		written, err := items[i].written, items[i].err
+		if items[i].skipped {
+			skipped++
+			summaries = append(summaries, generationSummary{Input: entry.url, Format: entry.formats[0], Size: entry.opts.Size, PayloadLength: len(entry.url), Error: "interrupted"})
+			continue
+		}
+		if err != nil {
+			// CSV rows are identified by line, the url can be missing
+			label := entry.url
+			if entry.line > 0 {
+				label = strings.TrimSpace(fmt.Sprintf("line %d %s", entry.line, entry.url))
+			}
+			failures = append(failures, fmt.Sprintf("%s: %v", label, err))
+			if entry.line > 0 {
+				err = fmt.Errorf("line %d: %w", entry.line, err)
+			}
+			written = append(written, generationSummary{Format: entry.formats[0], Size: entry.opts.Size, PayloadLength: len(entry.url), Error: err.Error()})
+		} else if !cli.json {
+			printSaved(cli, written)
+		}
+		for _, summary := range written {
+			summary.Input = entry.url
+			summaries = append(summaries, summary)
+		}
+	}
+
+	completed := len(entries) - len(failures) - skipped
+	code := 0
+	switch {
+	case skipped > 0:
+		code = errCodeInterrupted
+	case len(failures) > 0:
+		code = errCodeGeneralFailure
+	}
+
+	if cli.json {
+		if err := printJSON(cli.stdout, summaries); err != nil {
+			fmt.Fprintf(cli.stderr, "Error: %v\n", err)
+		}
+		return code
+	}
+
+	switch {
+	case skipped > 0:
+		fmt.Fprintf(cli.stderr, "Interrupted, %d of %d QR codes were generated.\n", completed, len(entries))
+	case cli.dryRun:
+		fmt.Fprintf(cli.notices, "Dry run, %d of %d QR codes would be generated.\n", completed, len(entries))
+	default:
+		fmt.Fprintf(cli.notices, "Generated %d of %d QR codes.\n", completed, len(entries))
+	}
+	if len(failures) > 0 {
+		fmt.Fprintf(cli.stderr, "Failed to generate %d QR codes:\n", len(failures))
+		for _, failure := range failures {
+			fmt.Fprintf(cli.stderr, "  %s\n", failure)
+		}
+	}
+
+	return code
+}
+
+// generateBatchItem generates and saves QR code for index-th entry of the batch in every format,
+// summaries of files written before failure are returned along with error. Files are checked
+// and written holding writeMu, so concurrent workers do not race on duplicate URLs.
+func generateBatchItem(entry batchEntry, index int, cli cliOptions, writeMu *sync.Mutex) ([]generationSummary, error) {
+	if entry.err != nil {
+		return nil, entry.err
+	}
+	url, opts, formats, sizes := appendQuery(entry.url, cli.query), entry.opts, entry.formats, entry.sizes
+	if err := checkURL(url, cli.strict); err != nil {
+		return nil, err
+	}
+
+	opts.Content = url
+	filename := autoFilename(url, formats[0], cli.tsFormat, cli.nameFrom, cli.noHash)
+	if len(entry.filename) > 0 {
+		filename = buildOutputName(entry.filename, formats)
+	} else if len(cli.nameTemplate) > 0 {
+		filename = templateFilename(cli.nameTemplate, index, opts, cli.images, formats)
+	} else if cli.deterministic {
+		filename = hashFilename(opts, cli.images, formats[0])
+	}
+	paths := outputPaths(filepath.Join(cli.dir, filename), formats, sizes)
+	if err := checkPaths(paths, cli); err != nil {
+		return nil, err
+	}
+
+	verboseLog.Printf("Generating %s (%d bytes)", url, len(url))
+	ctx, cancel := withTimeout(context.Background(), cli.timeout)
+	defer cancel()
+	results, err := qrgen.GenerateSizesContext(ctx, opts, formats, sizes)
+	if err != nil {
+		return nil, err
+	}
+	result := results[0]
+	verboseLog.Printf("Encoded as QR version %d, level %s", result.QRCode.VersionNumber, qrgen.LevelName(result.QRCode.Level))
+	if cli.verify {
+		if err := verifyResults(results, opts, formats, sizes); err != nil {
+			return nil, err
+		}
+	}
+
+	writeMu.Lock()
+	defer writeMu.Unlock()
+
+	if cli.info {
+		fmt.Fprintf(cli.stderr, "%s:\n", url)
+		printInfo(cli.stderr, result.QRCode, opts.Border)
+	}
+
+	// Another worker could have written the same file in the meantime
+	if err := checkPaths(paths, cli); err != nil {
+		return nil, err
+	}
+	return writeResults(results, opts, formats, sizes, paths, cli)
+}
+
+// checkPaths checks every output path of batch item for overwrite, files are never
+// overwritten with -increment
+func checkPaths(paths []string, cli cliOptions) error {
+	if cli.increment {
+		return nil
+	}
+	for _, path := range paths {
+		if err := checkOverwrite(path, cli.force); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// run parses command line arguments, generates QR codes and returns exit code. Only requested data
+// goes to stdout, i.e. image, data URI, JSON and decoded content, console preview and messages go
+// to stderr so stdout can be piped.
+func run(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet(filepath.Base(os.Args[0]), flag.ContinueOnError)
+	fs.SetOutput(stderr)
 
 	// Parse command string flags
-	urlFlag := flag.String("u", "", "URL to generate QR code for (max URL length 2048)")
-	levelFlag := flag.String("l", "M", "Correction level (L, M, Q, H)")
-	formatFlag := flag.String("f", "png", "Output format (png, svg)")
-	sizeFlag := flag.Int("s", 256, "Size of the QR code (default 256, min 100, max 4096)")
-	dirFlag := flag.String("d", ".", "Directory to save the file (default is current directory)")
-	fileFlag := flag.String("o", "", "Filename to save QR code to")
-	dispFlag := flag.Bool("nodisplay", false, "Set this flag to skip QR code output to console")
-	flag.Parse()
+	urlFlag := fs.String("u", "", "URL to generate QR code for (max URL length 2048), '-' reads URLs from stdin")
+	var textFlag string
+	fs.StringVar(&textFlag, "t", "", "Arbitrary text to generate QR code for, limited only by QR code capacity")
+	fs.StringVar(&textFlag, "text", "", "Same as -t")
+	strictFlag := fs.Bool("strict", false, "Reject URLs without scheme and host, and colors with contrast ratio below 3:1")
+	forceFlag := fs.Bool("force", false, "Overwrite output file if it already exists")
+	mkdirFlag := fs.Bool("mkdir", false, "Create output directory if it does not exist")
+	serveFlag := fs.String("serve", "", "Start HTTP server on address, e.g. :8080, answering GET /qr?data=...")
+	dryRunFlag := fs.Bool("dry-run", false, "Validate and generate QR codes without writing files, print where they would be saved")
+	verifyFlag := fs.Bool("verify", false, "Decode every generated image and fail if it does not hold the payload; svg, pdf, eps and text formats are checked on a raster rendering of their modules, without svg -shape, -eyestyle and -gradient")
+	jsonFlag := fs.Bool("json", false, "Print JSON summary of the generated files instead of messages")
+	infoFlag := fs.Bool("info", false, "Print QR version, correction level, dimension and payload length to stderr")
+	capacityFlag := fs.Bool("capacity", false, "Print the encoding mode of the payload and its capacity at every correction level for the resulting QR version, then exit")
+	incrementFlag := fs.Bool("increment", false, "Append counter to the filename if output file already exists")
+	timeoutFlag := fs.Duration("timeout", 0, "Maximum generation time of one QR code, e.g. 5s (default no limit)")
+	jobsFlag := fs.Int("jobs", runtime.NumCPU(), "Number of QR codes generated concurrently in batch mode")
+	retriesFlag := fs.Int("retries", 0, "Number of times a file write failing with a transient error, e.g. on network shares, is retried")
+	payloadFileFlag := fs.String("file", "", "File whose raw contents are encoded in the QR code")
+	inputFlag := fs.String("i", "", "File with URLs to generate QR codes for, one per line")
+	decodeFlag := fs.String("decode", "", "Print content of QR code in png, jpeg, gif, webp or tiff image instead of generating one, '-' reads stdin")
+	appendQueryFlag := queryParams{}
+	fs.Var(appendQueryFlag, "append-query", "Query parameter key=value merged into every URL, e.g. utm_source=qr, can be repeated")
+	csvFlag := fs.String("csv", "", "CSV file with header and columns url, filename, size, level, format overriding the flags per row")
+	levelFlag := fs.String("l", "M", "Correction level (L, M, Q, H, auto)")
+	formatFlag := fs.String("f", "png", "Output format (png, svg, jpeg, gif, webp, tiff, pdf, eps, matrix, json, txt, ansi), comma-separated list writes several formats")
+	sizeFlag := fs.String("s", strconv.Itoa(qrgen.DefaultSize), "Size of the QR code (min 100, max 4096), comma-separated list writes every size, e.g. 256,512 as name@256.png and name@512.png")
+	qrVersionFlag := fs.Int("qrversion", 0, "Force QR version (min 1, max 40) for a fixed module count, fails if the payload does not fit (default smallest version)")
+	maskFlag := fs.Int("mask", 0, "Force mask pattern (min 0, max 7) instead of the automatically selected one (not supported by the encoder yet)")
+	microFlag := fs.Bool("micro", false, "Generate Micro QR code for tiny payloads (not supported by the encoder yet)")
+	scaleFlag := fs.Int("scale", 0, "Pixels per module replacing -s, image size follows the module count (min 1, max 100)")
+	mmFlag := fs.Float64("mm", 0, "Physical width in millimeters, replaces -s with the pixel size at -dpi")
+	dpiFlag := fs.Int("dpi", qrgen.DefaultDPI, "Print resolution of -mm in pixels per inch (min 72, max 2400)")
+	dirFlag := fs.String("d", ".", "Directory to save the file (default is current directory)")
+	deterministicFlag := fs.Bool("deterministic", false, "Name auto-generated files by hash of payload and settings instead of time, omit time from metadata")
+	nameTemplateFlag := fs.String("name-template", "", "Filename pattern of generated files with placeholders {index}, {date}, {payload}, {hash} and {ext}, e.g. '{date}_{index}.{ext}'")
+	tsFormatFlag := fs.String("tsformat", defaultTimestampFormat, "Go time layout of the timestamp in auto-generated filenames")
+	nameFromFlag := fs.String("name-from", nameFromPayload, "Source of auto-generated filenames (payload, host), host uses the host of URL payloads")
+	noHashFlag := fs.Bool("no-hash", false, "Omit the short payload hash from auto-generated filenames")
+	asciiNamesFlag := fs.Bool("ascii-names", false, "Keep only ASCII letters and digits in filenames instead of all Unicode letters and digits")
+	fileFlag := fs.String("o", "", "Filename to save QR code to, '-' writes the image to stdout")
+	metadataFlag := fs.Bool("metadata", false, "Store payload and generation time as text metadata of png output")
+	compressFlag := fs.String("compress", qrgen.CompressDeflate, "Compression of tiff output (deflate, none)")
+	pngLevelFlag := fs.String("png-level", qrgen.PNGBest, "Compression level of png output (speed, default, best)")
+	losslessFlag := fs.Bool("lossless", true, "Lossless compression of webp output, lossy compression is not supported yet")
+	qualityFlag := fs.Int("quality", qrgen.DefaultQuality, "Quality of jpeg output (min 1, max 100)")
+	borderFlag := fs.Int("border", qrgen.DefaultBorder, "Quiet zone width in modules, 0 disables it")
+	logoFlag := fs.String("logo", "", "PNG or JPEG logo to place in the center of png, jpeg, gif, webp and tiff output")
+	bgImageFlag := fs.String("bg-image", "", "PNG or JPEG image filling the background of png, jpeg, gif, webp and tiff output behind the modules")
+	unitFlag := fs.Int("unit", qrgen.DefaultUnit, "Size of one module in pixels for svg output (min 1, max 100)")
+	responsiveFlag := fs.Bool("responsive", false, "Scale svg output to its container using viewBox instead of fixed pixel size")
+	altFlag := fs.String("alt", "", "Accessible title of svg output read by screen readers (default is the encoded content)")
+	asciiFlag := fs.Bool("ascii", false, "Write matrix output with # and space instead of 1 and 0")
+	minifyFlag := fs.Bool("minify", false, "Write svg output on a single line without whitespace between elements")
+	radiusFlag := fs.Float64("radius", 0, "Corner radius of modules of raster output as fraction of module size (min 0, max 0.5)")
+	shapeFlag := fs.String("shape", qrgen.ShapeSquare, "Module shape of svg output (square, circle)")
+	eyeStyleFlag := fs.String("eyestyle", qrgen.EyeSquare, "Finder pattern style of svg output (square, rounded)")
+	gradientFlag := fs.String("gradient", "", "Gradient module fill of svg output, e.g. '#f00,#00f' or '#f00,#00f@45' (angle in degrees)")
+	captionFlag := fs.String("caption", "", "Text label drawn below the QR code of png, jpeg, gif, webp, tiff and svg output")
+	captionSizeFlag := fs.Int("caption-size", qrgen.DefaultCaptionSize, "Font height of -caption in pixels (min 6, max 200)")
+	canvasFlag := fs.String("canvas", "", "Center the QR code in a WxH pixel canvas filled with -bg, e.g. 800x600, for raster and svg output")
+	frameFlag := fs.Bool("frame", false, "Draw a rounded frame with a text banner around the QR code of raster output")
+	frameTextFlag := fs.String("frame-text", qrgen.DefaultFrameText, "Banner text of -frame, empty draws the frame without banner")
+	dataURIFlag := fs.Bool("datauri", false, "Print the image as base64 data URI instead of saving a file")
+	clipboardFlag := fs.Bool("clipboard", false, "Copy the png image to the system clipboard instead of saving a file")
+	var consoleStyle string
+	fs.StringVar(&consoleStyle, "console", "small", "Console preview style (small, halfblock, full, ascii)")
+	fs.StringVar(&consoleStyle, "console-style", "small", "Same as -console")
+	negativeFlag := fs.Bool("negative", false, "Generate light modules on dark background, may not scan on all devices")
+	invertFlag := fs.Bool("invert", false, "Swap dark and light modules in console preview")
+	colorFlag := fs.Bool("color", false, "Render console preview with ANSI background colors (terminal only)")
+	dispFlag := fs.Bool("nodisplay", false, "Set this flag to skip QR code output to console")
+	fgFlag := fs.String("fg", "#000000", "Foreground color in hex (#rgb or #rrggbb)")
+	bgFlag := fs.String("bg", "#ffffff", "Background color in hex (#rgb or #rrggbb), or 'none' for transparent svg and png")
+	borderColorFlag := fs.String("border-color", "", "Quiet zone color in hex (#rgb or #rrggbb) of raster and svg output (default is -bg)")
+	transparentFlag := fs.Bool("transparent", false, "Make light modules of png or svg output transparent, same as -bg none")
+	completionFlag := fs.String("completion", "", "Print shell completion script (bash, zsh, fish) and exit")
+	configFlag := fs.String(configFlagName, "", "JSON file of default flag values, e.g. {\"s\": 512, \"f\": \"svg\"}, command line flags take precedence")
+	versionFlag := fs.Bool("version", false, "Print version information and exit")
+	listFormatsFlag := fs.Bool("list-formats", false, "Print supported output formats with their file extensions, one per line, and exit")
+	verboseFlag := fs.Bool("v", false, "Log generation steps to stderr")
+	var quietFlag bool
+	fs.BoolVar(&quietFlag, "q", false, "Print nothing but errors, no console preview, saved files, warnings or progress; -json, -info and -v still print")
+	fs.BoolVar(&quietFlag, "quiet", false, "Same as -q")
+	wifiFlag := fs.Bool("wifi", false, "Generate WiFi network join code from -ssid, -password, -auth and -hidden")
+	ssidFlag := fs.String("ssid", "", "WiFi network name")
+	passwordFlag := fs.String("password", "", "WiFi network password")
+	authFlag := fs.String("auth", "WPA", "WiFi authentication type (WPA, WEP, nopass)")
+	hiddenFlag := fs.Bool("hidden", false, "WiFi network is hidden")
+	vcardFlag := fs.Bool("vcard", false, "Generate contact card code from -name, -phone, -email, -org and -url")
+	nameFlag := fs.String("name", "", "Contact full name")
+	phoneFlag := fs.String("phone", "", "Contact phone number")
+	emailFlag := fs.String("email", "", "Contact email address")
+	orgFlag := fs.String("org", "", "Contact organization")
+	contactURLFlag := fs.String("url", "", "Contact website URL")
+	mailtoFlag := fs.Bool("mailto", false, "Generate email code from -to, -subject and -body")
+	toFlag := fs.String("to", "", "Email recipients, comma-separated")
+	subjectFlag := fs.String("subject", "", "Email subject")
+	bodyFlag := fs.String("body", "", "Email body or SMS message")
+	smsFlag := fs.String("sms", "", "Generate SMS code for phone number, message is taken from -body")
+	telFlag := fs.String("tel", "", "Generate code dialing phone number")
+	geoFlag := fs.String("geo", "", "Generate location code from 'lat,lon' or 'lat,lon,alt' coordinates")
+	labelFlag := fs.String("label", "", "Location label shown by map applications")
+	eventTitleFlag := fs.String("event-title", "", "Generate calendar event code with title, see -event-start, -event-end, -event-location")
+	eventStartFlag := fs.String("event-start", "", "Event start time, e.g. '2024-05-01 18:00' (local) or RFC 3339")
+	eventEndFlag := fs.String("event-end", "", "Event end time, optional, must be after start")
+	eventLocationFlag := fs.String("event-location", "", "Event location")
 
-	// Display defaults if no flags provided
-	flag.Usage = customUsage
-	if flag.NFlag() == 0 {
-		flag.Usage()
-		os.Exit(errCodeCommandLineUsageError)
+	// Sources of flag values, filled in once flags are parsed and before payload modes are checked
+	var sources map[string]string
+
+	// Payload modes in the order of usage examples, at most one can be selected
+	eventMode := func() bool { return isFlagSet(sources, "event-title") || isFlagSet(sources, "event-start") }
+	modes := []payloadMode{
+		{
+			flag:    "wifi",
+			example: "-wifi -ssid 'Home' -password 'secret' -auth WPA",
+			active:  func() bool { return *wifiFlag },
+			payload: func() (string, string, error) {
+				payload, err := qrgen.WiFiPayload(*ssidFlag, *passwordFlag, *authFlag, *hiddenFlag)
+				return payload, "wifi_" + *ssidFlag, err
+			},
+			errCode: errCodeCommandLineUsageError,
+		},
+		{
+			flag:    "vcard",
+			example: "-vcard -name 'Jane Doe' -phone '+1 555 0100' -email 'jane@example.com'",
+			active:  func() bool { return *vcardFlag },
+			payload: func() (string, string, error) {
+				payload, err := qrgen.VCardPayload(*nameFlag, *phoneFlag, *emailFlag, *orgFlag, *contactURLFlag)
+				return payload, "vcard_" + *nameFlag, err
+			},
+			errCode: errCodeCommandLineUsageError,
+		},
+		{
+			flag:    "mailto",
+			example: "-mailto -to 'support@example.com' -subject 'Help request'",
+			active:  func() bool { return *mailtoFlag },
+			payload: func() (string, string, error) {
+				payload, err := qrgen.MailtoPayload(*toFlag, *subjectFlag, *bodyFlag)
+				return payload, "mailto_" + *toFlag, err
+			},
+			errCode: errCodeCommandLineUsageError,
+		},
+		{
+			flag:    "sms",
+			example: "-sms '+1 555-0100' -body 'STOP'",
+			active:  func() bool { return isFlagSet(sources, "sms") },
+			payload: func() (string, string, error) {
+				payload, err := qrgen.SMSPayload(*smsFlag, *bodyFlag)
+				return payload, "sms_" + *smsFlag, err
+			},
+			errCode: errCodeCommandLineUsageError,
+		},
+		{
+			flag:    "tel",
+			example: "-tel '+1 555-0100'",
+			active:  func() bool { return isFlagSet(sources, "tel") },
+			payload: func() (string, string, error) {
+				payload, err := qrgen.TelPayload(*telFlag)
+				return payload, "tel_" + *telFlag, err
+			},
+			errCode: errCodeCommandLineUsageError,
+		},
+		{
+			flag:    "geo",
+			example: "-geo '52.5163,13.3777' -label 'Brandenburg Gate'",
+			active:  func() bool { return isFlagSet(sources, "geo") },
+			payload: func() (string, string, error) {
+				payload, err := qrgen.GeoPayload(*geoFlag, *labelFlag)
+				return payload, "geo_" + *geoFlag, err
+			},
+			errCode: errCodeCommandLineUsageError,
+		},
+		{
+			flag:    "event-title",
+			example: "-event-title 'Launch party' -event-start '2024-05-01 18:00' -event-location 'Main hall'",
+			active:  eventMode,
+			payload: func() (string, string, error) {
+				payload, err := eventPayload(*eventTitleFlag, *eventStartFlag, *eventEndFlag, *eventLocationFlag)
+				return payload, "event_" + *eventTitleFlag, err
+			},
+			errCode: errCodeCommandLineUsageError,
+		},
+		{
+			flag:    "t",
+			example: "-t 'Meeting room 4B, second floor'",
+			active:  func() bool { return len(textFlag) > 0 },
+			payload: func() (string, string, error) {
+				return textFlag, textFlag, nil
+			},
+			errCode: errCodeCommandLineUsageError,
+		},
+		{
+			flag:    "file",
+			example: "-file config.json -l L",
+			active:  func() bool { return len(*payloadFileFlag) > 0 },
+			payload: func() (string, string, error) {
+				payload, err := readPayloadFile(*payloadFileFlag)
+				return payload, filepath.Base(*payloadFileFlag), err
+			},
+			errCode: errCodeGeneralFailure,
+		},
 	}
+	fs.Usage = func() { customUsage(fs, modes) }
 
-	// Check URL length
-	if len(*urlFlag) == 0 {
-		fmt.Printf("Error: URL is required. Please use -u <URL>\n")
-		os.Exit(errCodeCommandLineUsageError)
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return errCodeCommandLineUsageError
 	}
-	if len(*urlFlag) > maxURLLength {
-		fmt.Printf("Error: URL must be less than %d characters.\n", maxURLLength)
-		os.Exit(errCodeCommandLineUsageError)
+
+	// Environment and config file values fill in flags missing from the command line before any
+	// of them is used
+	sources = flagSources(fs)
+	if err := applyEnv(fs, os.Getenv, sources); err != nil {
+		fmt.Fprintf(stderr, "Error: %v.\n", err)
+		return errCodeCommandLineUsageError
+	}
+	if len(*configFlag) > 0 {
+		if err := applyConfig(fs, *configFlag, sources); err != nil {
+			fmt.Fprintf(stderr, "Error: %v.\n", err)
+			return errCodeCommandLineUsageError
+		}
 	}
 
-	// Check QR size
-	if *sizeFlag < minQRSize || *sizeFlag > maxQRSize {
-		fmt.Fprintf(os.Stderr, "Error: Size of the QR code must be between %d and %d.\n", minQRSize, maxQRSize)
-		os.Exit(errCodeCommandLineUsageError)
+	if *versionFlag {
+		printVersion(stdout)
+		return 0
+	}
+
+	if *listFormatsFlag {
+		printFormats(stdout)
+		return 0
+	}
+
+	if len(*completionFlag) > 0 {
+		if err := writeCompletion(stdout, fs, *completionFlag); err != nil {
+			fmt.Fprintf(stderr, "Error: %v.\n", err)
+			return errCodeCommandLineUsageError
+		}
+		return 0
+	}
+
+	// Logger and filename sanitizer are shared by helpers, reset them in case run is called again
+	sanitizeFilename = qrgen.SanitizeFilename
+	if *asciiNamesFlag {
+		sanitizeFilename = qrgen.SanitizeASCIIFilename
+	}
+	verboseLog.SetOutput(io.Discard)
+	if *verboseFlag {
+		verboseLog.SetOutput(stderr)
+		fs.Visit(func(f *flag.Flag) {
+			verboseLog.Printf("Flag -%s=%q from %s", f.Name, f.Value.String(), sources[f.Name])
+		})
+	}
+
+	// Decoding reads an image instead of generating one
+	if len(*decodeFlag) > 0 {
+		if len(*urlFlag) > 0 || len(*inputFlag) > 0 || len(*csvFlag) > 0 || len(*serveFlag) > 0 || len(activeModes(modes)) > 0 {
+			fmt.Fprintf(stderr, "Error: -decode cannot be combined with -serve or other input flags such as -u, -i, -csv and -t.\n")
+			return errCodeCommandLineUsageError
+		}
+		return decodeQR(*decodeFlag, *jsonFlag, stdout, stderr)
+	}
+
+	// Status messages and warnings are silenced with -quiet, errors never are
+	notices := stderr
+	if quietFlag {
+		notices = io.Discard
+	}
+
+	// Display defaults if no flags provided and nothing is piped in, environment and config file
+	// only fill in defaults
+	if !hasCommandLineFlags(sources) && !stdinIsPiped() {
+		fs.Usage()
+		return errCodeCommandLineUsageError
+	}
+
+	// Check input source, multiple URLs are generated in batch mode.
+	// Payload of single QR code goes to content, name is used for auto-generated filename.
+	content, name := *urlFlag, *urlFlag
+	urlPayload := true
+	// Server takes content from requests, stdin is never read then
+	serving := len(*serveFlag) > 0
+	if serving && *urlFlag == "-" {
+		fmt.Fprintf(stderr, "Error: -serve cannot read URLs from stdin with -u -.\n")
+		return errCodeCommandLineUsageError
+	}
+	var batchURLs []string
+	active := activeModes(modes)
+	switch {
+	case len(active) > 1:
+		fmt.Fprintf(stderr, "Error: Only one of %s can be used.\n", modeFlagList(modes))
+		return errCodeCommandLineUsageError
+	case len(active) == 1:
+		mode := active[0]
+		if len(*urlFlag) > 0 || len(*inputFlag) > 0 || len(*csvFlag) > 0 {
+			fmt.Fprintf(stderr, "Error: -%s cannot be combined with -u, -i or -csv.\n", mode.flag)
+			return errCodeCommandLineUsageError
+		}
+		payload, modeName, err := mode.payload()
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: %v.\n", err)
+			return mode.errCode
+		}
+		content, name, urlPayload = payload, modeName, false
+	case len(*csvFlag) > 0:
+		if len(*urlFlag) > 0 || len(*inputFlag) > 0 || len(*fileFlag) > 0 {
+			fmt.Fprintf(stderr, "Error: -csv cannot be combined with -u, -i or -o.\n")
+			return errCodeCommandLineUsageError
+		}
+	case len(*inputFlag) > 0:
+		if len(*urlFlag) > 0 || len(*fileFlag) > 0 {
+			fmt.Fprintf(stderr, "Error: -i cannot be combined with -u or -o.\n")
+			return errCodeCommandLineUsageError
+		}
+		urls, err := readURLList(*inputFlag)
+		if err != nil {
+			return reportError(stderr, err, errCodeGeneralFailure)
+		}
+		batchURLs = urls
+	case !serving && (*urlFlag == "-" || (len(*urlFlag) == 0 && stdinIsPiped())):
+		urls, err := readURLs(os.Stdin)
+		if err != nil {
+			return reportError(stderr, err, errCodeGeneralFailure)
+		}
+		if len(urls) == 1 {
+			content, name = urls[0], urls[0]
+		} else if len(urls) > 1 {
+			if len(*fileFlag) > 0 {
+				fmt.Fprintf(stderr, "Error: -o cannot be used with multiple URLs from stdin.\n")
+				return errCodeCommandLineUsageError
+			}
+			batchURLs = urls
+		} else {
+			content = ""
+		}
+	}
+	batch := len(*inputFlag) > 0 || len(*csvFlag) > 0 || len(batchURLs) > 0
+
+	// Data URI replaces the file output of a single QR code
+	if *dataURIFlag && (batch || len(*fileFlag) > 0) {
+		fmt.Fprintf(stderr, "Error: -datauri cannot be combined with -o or multiple URLs.\n")
+		return errCodeCommandLineUsageError
+	}
+
+	if *capacityFlag && batch {
+		fmt.Fprintf(stderr, "Error: -capacity cannot be combined with multiple URLs.\n")
+		return errCodeCommandLineUsageError
+	}
+
+	// Clipboard holds one image, as does the data URI
+	if *clipboardFlag && (batch || len(*fileFlag) > 0 || *dataURIFlag || *jsonFlag) {
+		fmt.Fprintf(stderr, "Error: -clipboard cannot be combined with -o, -datauri, -json or multiple URLs.\n")
+		return errCodeCommandLineUsageError
+	}
+
+	// Check console preview style
+	if !slices.Contains(consoleStyles, consoleStyle) {
+		fmt.Fprintf(stderr, "Error: Invalid console style '%s'. Choose from %s.\n", consoleStyle, strings.Join(consoleStyles, ", "))
+		return errCodeCommandLineUsageError
+	}
+
+	// JSON summary shares stdout with image data
+	if *jsonFlag && (*dataURIFlag || *fileFlag == stdoutFilename) {
+		fmt.Fprintf(stderr, "Error: -json cannot be combined with -datauri or -o -.\n")
+		return errCodeCommandLineUsageError
+	}
+
+	// Server takes content from requests instead of flags
+	if serving && (batch || len(*fileFlag) > 0 || *dataURIFlag || *clipboardFlag || *jsonFlag) {
+		fmt.Fprintf(stderr, "Error: -serve cannot be combined with -i, -o, -datauri, -clipboard or -json.\n")
+		return errCodeCommandLineUsageError
+	}
+
+	if !batch && urlPayload && !serving {
+		// Check URL length
+		if len(content) == 0 {
+			fmt.Fprintf(stderr, "Error: URL is required. Please use -u <URL> or -i <file>\n")
+			return errCodeCommandLineUsageError
+		}
+		content = appendQuery(content, url.Values(appendQueryFlag))
+		if err := checkURL(content, *strictFlag); err != nil {
+			fmt.Fprintf(stderr, "Error: %v.\n", err)
+			return errCodeCommandLineUsageError
+		}
+	}
+
+	// Encoder has no Micro QR symbols, refuse instead of silently producing a regular code
+	if *microFlag {
+		fmt.Fprintf(stderr, "Error: Micro QR codes are not supported by the QR encoder yet, omit -micro to generate a regular QR code.\n")
+		return errCodeCommandLineUsageError
+	}
+
+	// Encoder always selects the mask with the lowest penalty and has no way to override it
+	if isFlagSet(sources, "mask") {
+		if *maskFlag < 0 || *maskFlag > 7 {
+			fmt.Fprintf(stderr, "Error: -mask must be between 0 and 7.\n")
+			return errCodeCommandLineUsageError
+		}
+		fmt.Fprintf(stderr, "Error: Forcing mask pattern %d is not supported by the QR encoder yet, omit -mask to use the automatically selected pattern.\n", *maskFlag)
+		return errCodeCommandLineUsageError
+	}
+
+	if sources["frame-text"] == sourceCommandLine && !*frameFlag {
+		fmt.Fprintf(stderr, "Error: -frame-text requires -frame.\n")
+		return errCodeCommandLineUsageError
+	}
+
+	// Scale sets the size in place of -s and -mm. Only the command line conflicts, otherwise a flag
+	// given on it wins over the other one from environment or config file.
+	sizeGiven := sources["s"] == sourceCommandLine || sources["mm"] == sourceCommandLine
+	if *scaleFlag != 0 && sizeGiven {
+		if sources["scale"] == sourceCommandLine {
+			fmt.Fprintf(stderr, "Error: -scale cannot be combined with -s or -mm.\n")
+			return errCodeCommandLineUsageError
+		}
+		*scaleFlag = 0
+	}
+	if *scaleFlag != 0 {
+		*mmFlag = 0
+	}
+
+	// Transparent background replaces background color, with the same precedence as -scale
+	if *transparentFlag && sources["bg"] == sourceCommandLine {
+		if sources["transparent"] == sourceCommandLine {
+			fmt.Fprintf(stderr, "Error: -transparent and -bg cannot be combined.\n")
+			return errCodeCommandLineUsageError
+		}
+		*transparentFlag = false
+	}
+	if *transparentFlag {
+		*bgFlag = qrgen.TransparentColor
 	}
 
 	// Connect stadard correction levels to constants and check them
-	var level qrcode.RecoveryLevel
-	switch *levelFlag {
-	case "L":
-		level = qrcode.Low
-	case "M":
-		level = qrcode.Medium
-	case "Q":
-		level = qrcode.High
-	case "H":
-		level = qrcode.Highest
-	default:
-		fmt.Fprintf(os.Stderr, "Invalid correction level. Choose from L, M, Q, H.\n")
-		os.Exit(errCodeCommandLineUsageError)
+	autoLevel := *levelFlag == qrgen.LevelAuto
+	level := qrcode.Highest
+	var err error
+	if !autoLevel {
+		level, err = qrgen.ParseLevel(*levelFlag)
+		if err != nil {
+			fmt.Fprintf(stderr, "Invalid correction level. Choose from L, M, Q, H, auto.\n")
+			return errCodeCommandLineUsageError
+		}
 	}
 
-	// Check specified file format
-	if !isValidFormat(*formatFlag) {
-		fmt.Fprintf(os.Stderr, "Error: Unsupported file format '%s'. Only png and svg are supported.\n", *formatFlag)
-		os.Exit(errCodeCommandLineUsageError)
+	formats, err := qrgen.ParseFormatList(*formatFlag)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v.\n", err)
+		return errCodeCommandLineUsageError
 	}
 
-	//Generate QRcode
-	qr, err := qrcode.New(*urlFlag, level)
-	exitOnError(err)
+	sizes, err := qrgen.ParseSizeList(*sizeFlag)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v.\n", err)
+		return errCodeCommandLineUsageError
+	}
 
-	// Print QRcode to console if --nodisplay flag is not set
-	if !*dispFlag {
-		fmt.Println(qr.ToSmallString(false))
+	// Only files can hold several formats and sizes
+	if (len(formats) > 1 || len(sizes) > 1) && (*dataURIFlag || *clipboardFlag || *fileFlag == stdoutFilename) {
+		fmt.Fprintf(stderr, "Error: -datauri, -clipboard and -o - accept a single format and size only.\n")
+		return errCodeCommandLineUsageError
+	}
+	if *clipboardFlag && formats[0] != "png" {
+		fmt.Fprintf(stderr, "Error: -clipboard supports png format only.\n")
+		return errCodeCommandLineUsageError
+	}
+
+	opts := qrgen.Options{
+		Size:        sizes[0],
+		Scale:       *scaleFlag,
+		Millimeters: *mmFlag,
+		DPI:         *dpiFlag,
+		Level:       level,
+		AutoLevel:   autoLevel,
+		Version:     *qrVersionFlag,
+		Format:      formats[0],
+		Foreground:  *fgFlag,
+		Background:  *bgFlag,
+		Negative:    *negativeFlag,
+		BorderColor: *borderColorFlag,
+		Unit:        *unitFlag,
+		Shape:       *shapeFlag,
+		Radius:      *radiusFlag,
+		Responsive:  *responsiveFlag,
+		Minify:      *minifyFlag,
+		Alt:         *altFlag,
+		EyeStyle:    *eyeStyleFlag,
+		Gradient:    *gradientFlag,
+		Caption:     *captionFlag,
+		CaptionSize: *captionSizeFlag,
+		Frame:       *frameFlag,
+		FrameText:   *frameTextFlag,
+		Border:      *borderFlag,
+		Quality:     *qualityFlag,
+		ASCII:       *asciiFlag,
+		Metadata:    *metadataFlag,
+		Lossy:       !*losslessFlag,
+		Compression: *compressFlag,
+		PNGLevel:    *pngLevelFlag,
+	}
+	if opts.Metadata && !*deterministicFlag {
+		opts.Created = time.Now()
+	}
+	if len(*canvasFlag) > 0 {
+		opts.CanvasWidth, opts.CanvasHeight, err = qrgen.ParseCanvas(*canvasFlag)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: %v.\n", err)
+			return errCodeCommandLineUsageError
+		}
+	}
+	// Physical size replaces -s and goes through the same size limits
+	if opts.Millimeters > 0 {
+		opts.Size = qrgen.PixelSize(opts.Millimeters, opts.DPI)
+		sizes = []int{opts.Size}
+	}
+
+	if len(*logoFlag) > 0 {
+		opts.Logo, err = qrgen.LoadLogo(*logoFlag)
+		if err != nil {
+			return reportError(stderr, err, errCodeGeneralFailure)
+		}
+	}
+	if len(*bgImageFlag) > 0 {
+		opts.BackgroundImage, err = qrgen.LoadBackgroundImage(*bgImageFlag)
+		if err != nil {
+			return reportError(stderr, err, errCodeGeneralFailure)
+		}
+	}
+	// Deterministic names tell images apart by path, logo only codes keep their former names
+	images := *logoFlag
+	if len(*bgImageFlag) > 0 {
+		images += "|" + *bgImageFlag
+	}
+
+	// Check sizes, colors, formats and other generation options
+	for _, size := range sizes {
+		for _, format := range formats {
+			opts.Size, opts.Format = size, format
+			if err := opts.Validate(); err != nil {
+				if len(sizes) > 1 {
+					err = fmt.Errorf("size %d: %w", size, err)
+				}
+				fmt.Fprintf(stderr, "Error: %v.\n", err)
+				return errCodeCommandLineUsageError
+			}
+		}
+	}
+	opts.Size, opts.Format = sizes[0], formats[0]
+
+	if opts.Negative {
+		fmt.Fprintf(notices, "Warning: Negative QR codes may not scan on all devices.\n")
+	}
+
+	// Scanners need clearly distinguishable modules, transparent background depends on the surface
+	if ratio, ok := contrastRatio(opts); ok && ratio < qrgen.MinContrastRatio {
+		if *strictFlag {
+			fmt.Fprintf(stderr, "Error: Contrast ratio of foreground and background is %.1f:1, at least %g:1 is required to scan reliably.\n", ratio, qrgen.MinContrastRatio)
+			return errCodeCommandLineUsageError
+		}
+		fmt.Fprintf(notices, "Warning: Contrast ratio of foreground and background is %.1f:1, codes below %g:1 may not scan.\n", ratio, qrgen.MinContrastRatio)
+	}
+
+	// Logo covers part of the modules, keep enough error correction to restore them
+	if opts.Logo != nil && !opts.AutoLevel && opts.Level < qrcode.High {
+		fmt.Fprintf(notices, "Warning: Correction level raised to Q to keep the code with logo scannable.\n")
+	}
+
+	// Photo detail in light modules confuses scanners, only strong error correction helps
+	if opts.BackgroundImage != nil {
+		fmt.Fprintf(notices, "Warning: Background image can make the code hard to scan, use -l H and check it with -verify.\n")
+	}
+
+	if *dryRunFlag && (*fileFlag == stdoutFilename || *dataURIFlag || *clipboardFlag || serving) {
+		fmt.Fprintf(stderr, "Error: -dry-run cannot be combined with -o -, -datauri, -clipboard or -serve.\n")
+		return errCodeCommandLineUsageError
+	}
+
+	if serving {
+		return reportError(stderr, serveQR(*serveFlag, opts, *strictFlag, *timeoutFlag, notices), errCodeGeneralFailure)
 	}
 
-	// Prepare filename
 	dir, err := filepath.Abs(*dirFlag)
-	exitOnError(err)
+	if err != nil {
+		return reportError(stderr, err, errCodeGeneralFailure)
+	}
+
+	// Capacity preflight writes nothing
+	if *capacityFlag {
+		capacityOpts := opts
+		capacityOpts.Content = content
+		capacity, err := qrgen.PayloadCapacity(capacityOpts)
+		if err != nil {
+			return reportError(stderr, err, errCodeEncodingFailure)
+		}
+		printCapacity(stdout, capacity, len(content))
+		return 0
+	}
+
+	// Dry run must not create missing directory
+	fileOutput := *fileFlag != stdoutFilename && !*dataURIFlag && !*clipboardFlag
+	if fileOutput && !(*dryRunFlag && *mkdirFlag) {
+		if err := prepareDir(dir, *mkdirFlag); err != nil {
+			return reportError(stderr, err, errCodeWriteFailure)
+		}
+	}
 
-	currentTime := time.Now().Format("20060102150405")
+	if *timeoutFlag < 0 {
+		fmt.Fprintf(stderr, "Error: -timeout must not be negative.\n")
+		return errCodeCommandLineUsageError
+	}
+
+	if *jobsFlag < 1 {
+		fmt.Fprintf(stderr, "Error: -jobs must be at least 1.\n")
+		return errCodeCommandLineUsageError
+	}
 
+	if !slices.Contains(nameSources, *nameFromFlag) {
+		fmt.Fprintf(stderr, "Error: Invalid -name-from '%s'. Choose from %s.\n", *nameFromFlag, strings.Join(nameSources, ", "))
+		return errCodeCommandLineUsageError
+	}
+
+	if *retriesFlag < 0 {
+		fmt.Fprintf(stderr, "Error: -retries must not be negative.\n")
+		return errCodeCommandLineUsageError
+	}
+
+	if len(*nameTemplateFlag) > 0 {
+		if len(*fileFlag) > 0 {
+			fmt.Fprintf(stderr, "Error: -name-template cannot be combined with -o.\n")
+			return errCodeCommandLineUsageError
+		}
+		if err := checkNameTemplate(*nameTemplateFlag); err != nil {
+			fmt.Fprintf(stderr, "Error: %v.\n", err)
+			return errCodeCommandLineUsageError
+		}
+	}
+
+	if *forceFlag && *incrementFlag {
+		fmt.Fprintf(stderr, "Error: -force and -increment cannot be combined.\n")
+		return errCodeCommandLineUsageError
+	}
+
+	cli := cliOptions{
+		dir:           dir,
+		strict:        *strictFlag,
+		force:         *forceFlag,
+		increment:     *incrementFlag,
+		info:          *infoFlag,
+		tsFormat:      *tsFormatFlag,
+		nameFrom:      *nameFromFlag,
+		noHash:        *noHashFlag,
+		deterministic: *deterministicFlag,
+		images:        images,
+		json:          *jsonFlag,
+		jobs:          *jobsFlag,
+		timeout:       *timeoutFlag,
+		dryRun:        *dryRunFlag,
+		verify:        *verifyFlag,
+		retries:       *retriesFlag,
+		query:         url.Values(appendQueryFlag),
+		nameTemplate:  *nameTemplateFlag,
+		stdout:        stdout,
+		stderr:        stderr,
+		notices:       notices,
+	}
+
+	// Generate QR code for every URL in the input file, CSV rows or stdin
+	if batch {
+		entries := batchEntries(batchURLs, opts, formats, sizes)
+		if len(*csvFlag) > 0 {
+			entries, err = readCSVEntries(*csvFlag, opts, formats, sizes)
+			if err != nil {
+				return reportError(stderr, err, errCodeGeneralFailure)
+			}
+		}
+
+		// First SIGINT or SIGTERM stops the batch after the files in flight, the next one kills
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		context.AfterFunc(ctx, stop)
+		return generateBatch(ctx, entries, cli)
+	}
+
+	// Prepare filename
 	var outputFilename string
 
-	if len(*fileFlag) == 0 {
-		outputFilename = fmt.Sprintf("qrcode%s%s.%s", currentTime, sanitizeFilename(*urlFlag), *formatFlag)
+	opts.Content = content
+	if len(*nameTemplateFlag) > 0 {
+		outputFilename = templateFilename(*nameTemplateFlag, 1, opts, images, formats)
+	} else if len(*fileFlag) == 0 && *deterministicFlag {
+		outputFilename = hashFilename(opts, images, opts.Format)
+	} else if len(*fileFlag) == 0 {
+		outputFilename = autoFilename(name, opts.Format, *tsFormatFlag, *nameFromFlag, *noHashFlag)
 	} else {
-		outputFilename = sanitizeFilename(*fileFlag)
+		outputFilename = buildOutputName(*fileFlag, formats)
+	}
+
+	paths := outputPaths(filepath.Join(dir, outputFilename), formats, sizes)
+	// Pipe or device is written as named, without directory or extension
+	if fileOutput && len(*fileFlag) > 0 && isSpecialFile(*fileFlag) {
+		if len(paths) > 1 {
+			fmt.Fprintf(stderr, "Error: -o %s is a pipe or device, which takes a single format and size.\n", *fileFlag)
+			return errCodeCommandLineUsageError
+		}
+		path, err := filepath.Abs(*fileFlag)
+		if err != nil {
+			return reportError(stderr, err, errCodeWriteFailure)
+		}
+		paths = []string{path}
 	}
+	verboseLog.Printf("Output path resolved to %s", strings.Join(paths, ", "))
 
-	outputPath := filepath.Join(dir, outputFilename)
+	// Refuse to overwrite before doing any work
+	if fileOutput && !*incrementFlag {
+		for _, outputPath := range paths {
+			if err := checkOverwrite(outputPath, *forceFlag); err != nil {
+				fmt.Fprintf(stderr, "Error: %v.\n", err)
+				return errCodeWriteFailure
+			}
+		}
+	}
 
-	// Save file in selected format
-	switch *formatFlag {
-	case "png":
-		err = qr.WriteFile(*sizeFlag, outputPath)
-	case "svg":
-		svgStr := generateSVG(qr)
-		err = os.WriteFile(outputPath, []byte(svgStr), 0644)
-	default:
-		fmt.Fprintf(os.Stderr, "Invalid format. Choose from png or svg.\n")
-		os.Exit(errCodeCommandLineUsageError)
+	//Generate QRcode
+	verboseLog.Printf("Generating %s code from %d bytes payload", strings.Join(formats, ", "), len(content))
+	ctx, cancel := withTimeout(context.Background(), *timeoutFlag)
+	defer cancel()
+	results, err := qrgen.GenerateSizesContext(ctx, opts, formats, sizes)
+	if errors.Is(err, qrgen.ErrCapacityExceeded) && *vcardFlag {
+		fmt.Fprintf(stderr, "Error: vCard is too large for a QR code: %v, or use fewer fields.\n", err)
+		return errCodeEncodingFailure
+	}
+	if errors.Is(err, qrgen.ErrCapacityExceeded) && eventMode() {
+		fmt.Fprintf(stderr, "Error: Event is too large for a QR code: %v, e.g. title and location.\n", err)
+		return errCodeEncodingFailure
+	}
+	if errors.Is(err, qrgen.ErrCapacityExceeded) && len(*payloadFileFlag) > 0 {
+		fmt.Fprintf(stderr, "Error: File '%s' is too large for a QR code: %v.\n", *payloadFileFlag, err)
+		return errCodeEncodingFailure
+	}
+	if err != nil {
+		return reportError(stderr, err, errCodeEncodingFailure)
+	}
+	result := results[0]
+	verboseLog.Printf("Encoded as QR version %d, level %s, %d bytes of %s data", result.QRCode.VersionNumber, qrgen.LevelName(result.QRCode.Level), len(result.Data), opts.Format)
+
+	// Unreadable code is not written anywhere
+	if *verifyFlag {
+		if err := verifyResults(results, opts, formats, sizes); err != nil {
+			return reportError(stderr, err, errCodeEncodingFailure)
+		}
+	}
+
+	// Information goes to stderr to keep stdout clean for image output
+	if opts.AutoLevel && !*jsonFlag {
+		fmt.Fprintf(notices, "Correction level %s selected automatically.\n", qrgen.LevelName(result.QRCode.Level))
+	}
+	if *infoFlag {
+		printInfo(stderr, result.QRCode, opts.Border)
+	}
+
+	// Write raw image to stdout, console output would corrupt it
+	if *fileFlag == stdoutFilename {
+		if _, err := result.WriteTo(stdout); err != nil {
+			return reportError(stderr, err, errCodeWriteFailure)
+		}
+		return 0
+	}
+
+	// Print data URI for embedding in HTML/CSS
+	if *dataURIFlag {
+		fmt.Fprintln(stdout, qrgen.DataURI(result.Data, opts.Format))
+		return 0
+	}
+
+	if *clipboardFlag {
+		if err := copyToClipboard(result.Data); err != nil {
+			return reportError(stderr, err, errCodeWriteFailure)
+		}
+		fmt.Fprintln(notices, "QR code copied to clipboard.")
+		return 0
 	}
-	exitOnError(err)
 
-	fmt.Println("QR code saved as:", outputPath)
+	// Print QRcode to console if --nodisplay flag is not set. Preview goes to stderr with messages,
+	// so redirected stdout never gets it mixed into data.
+	if !*dispFlag && !*jsonFlag && !quietFlag {
+		// Escape sequences only make sense on terminal, fall back to blocks otherwise.
+		// Negative code is previewed negative as well.
+		invert := *invertFlag != opts.Negative
+		switch {
+		case *colorFlag && isTerminal(stderr):
+			fmt.Fprintln(stderr, qrgen.ANSIString(result.QRCode, opts.Border, invert))
+		case consoleStyle == "halfblock":
+			fmt.Fprintln(stderr, qrgen.HalfBlockString(result.QRCode, opts.Border, invert))
+		case consoleStyle == "full":
+			fmt.Fprintln(stderr, qrgen.FullString(result.QRCode, opts.Border, invert))
+		case consoleStyle == "ascii":
+			fmt.Fprintln(stderr, qrgen.ASCIIString(result.QRCode, opts.Border, invert))
+		default:
+			fmt.Fprintln(stderr, result.QRCode.ToSmallString(invert))
+		}
+	}
+
+	// Save file in every selected format
+	summaries, err := writeResults(results, opts, formats, sizes, paths, cli)
+	if err != nil {
+		return reportError(stderr, err, errCodeWriteFailure)
+	}
+
+	if *jsonFlag {
+		if len(summaries) == 1 {
+			err = printJSON(stdout, summaries[0])
+		} else {
+			err = printJSON(stdout, summaries)
+		}
+		if err != nil {
+			return reportError(stderr, err, errCodeGeneralFailure)
+		}
+		return 0
+	}
+
+	printSaved(cli, summaries)
+	return 0
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
 }
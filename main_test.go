@@ -0,0 +1,198 @@
+package main
+
+import (
+	"bytes"
+	"image/png"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/mtzvd/qr-generator/qrgen"
+)
+
+// pngTrailer ends every PNG file, the IEND chunk type and its CRC
+var pngTrailer = []byte("IEND\xaeB`\x82")
+
+func TestRunStdoutOnlyImage(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-u", "https://example.com", "-o", "-"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit code %d: %s", code, stderr.String())
+	}
+
+	data := stdout.Bytes()
+	if !bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")) {
+		t.Fatalf("stdout does not start with PNG signature: %q", data[:min(len(data), 16)])
+	}
+	if !bytes.HasSuffix(data, pngTrailer) {
+		t.Fatalf("stdout has bytes after PNG trailer")
+	}
+	if _, err := png.Decode(bytes.NewReader(data)); err != nil {
+		t.Fatalf("stdout is not a PNG image: %v", err)
+	}
+}
+
+func TestRunPreviewOnStderr(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+
+	var stdout, stderr bytes.Buffer
+	args := []string{"-u", "https://example.com", "-d", dir, "-o", "code.png"}
+	if code := run(args, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit code %d: %s", code, stderr.String())
+	}
+	if _, err := os.Stat(filepath.Join(dir, "code.png")); err != nil {
+		t.Errorf("image not written into -d directory: %v", err)
+	}
+	if stdout.Len() > 0 {
+		t.Errorf("stdout got %d bytes, want none: %q", stdout.Len(), stdout.String())
+	}
+	if !bytes.Contains(stderr.Bytes(), []byte("█")) {
+		t.Errorf("stderr has no console preview: %q", stderr.String())
+	}
+}
+
+func TestRunServeRejectsStdinURL(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-serve", "127.0.0.1:0", "-u", "-"}, &stdout, &stderr); code != errCodeCommandLineUsageError {
+		t.Fatalf("exit code %d, want %d: %s", code, errCodeCommandLineUsageError, stderr.String())
+	}
+}
+
+func TestAutoFilenameLongPayload(t *testing.T) {
+	long := strings.Repeat("x", 400)
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"ascii", long},
+		{"multibyte", strings.Repeat("я", 400)},
+		{"host", "https://" + strings.Repeat("a", 240) + ".com/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, nameFrom := range nameSources {
+				name := autoFilename(tt.content, "png", "20060102150405", nameFrom, false)
+				if len(name) > 255 {
+					t.Errorf("name of %d bytes exceeds filesystem limit", len(name))
+				}
+				if !utf8.ValidString(name) {
+					t.Errorf("truncated name %q is not valid UTF-8", name)
+				}
+			}
+
+			opts := qrgen.DefaultOptions()
+			opts.Content = tt.content
+			name := templateFilename("{payload}.{ext}", 1, opts, "", []string{"png"})
+			if len(name) > 255 || !utf8.ValidString(name) {
+				t.Errorf("templated name %q is not a valid filename", name)
+			}
+		})
+	}
+
+	// Hash of whole payload keeps payloads with the same beginning apart
+	if autoFilename(long+"a", "png", "2006", nameFromPayload, false) == autoFilename(long+"b", "png", "2006", nameFromPayload, false) {
+		t.Errorf("long payloads differing at the end got the same name")
+	}
+}
+
+// Environment and config file fill in defaults, flags given on the command line take precedence
+// over them instead of conflicting
+func TestRunDefaultsYieldToCommandLine(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("QRGEN_SIZE", "512")
+	config := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(config, []byte(`{"bg": "#eeeeee", "scale": 3}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		code int
+	}{
+		{"scale over environment size", []string{"-scale", "4"}, 0},
+		{"transparent over config bg", []string{"-config", config, "-transparent"}, 0},
+		{"size over config scale", []string{"-config", config, "-s", "300"}, 0},
+		{"command line conflict", []string{"-scale", "4", "-s", "300"}, errCodeCommandLineUsageError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			args := append([]string{"-u", "https://example.com", "-o", "-"}, tt.args...)
+			if code := run(args, &stdout, &stderr); code != tt.code {
+				t.Errorf("exit code %d, want %d: %s", code, tt.code, stderr.String())
+			}
+		})
+	}
+}
+
+func TestBuildOutputName(t *testing.T) {
+	tests := []struct {
+		userName string
+		formats  []string
+		want     string
+	}{
+		{"logo.png", []string{"png"}, "logo.png"},
+		{"logo", []string{"png"}, "logo.png"},
+		{"my.file", []string{"png"}, "my_file.png"},
+		{"my.file.png", []string{"png"}, "my_file.png"},
+		{"photo.JPG", []string{"jpeg"}, "photo.JPG"},
+		{"logo.png", []string{"svg", "png"}, "logo.svg"},
+		{"logo.svg", []string{"svg", "png"}, "logo.svg"},
+		{"photo.jpg", []string{"png", "jpeg"}, "photo.png"},
+		{"scan.tif", []string{"pdf", "tiff"}, "scan.pdf"},
+		{"logo.gif", []string{"svg", "png"}, "logo_gif.svg"},
+	}
+
+	for _, tt := range tests {
+		if got := buildOutputName(tt.userName, tt.formats); got != tt.want {
+			t.Errorf("buildOutputName(%q, %v) = %q, want %q", tt.userName, tt.formats, got, tt.want)
+		}
+	}
+}
+
+func TestAppendQuery(t *testing.T) {
+	params := url.Values{"utm": {"qr"}}
+	tests := []struct {
+		rawURL string
+		params url.Values
+		want   string
+	}{
+		{"https://a.com/", params, "https://a.com/?utm=qr"},
+		{"https://a.com/?b=1&a=x+y&flag", params, "https://a.com/?b=1&a=x+y&flag&utm=qr"},
+		{"https://a.com/?q=%2F&utm=old", params, "https://a.com/?q=%2F&utm=qr"},
+		{"https://a.com/p#top", params, "https://a.com/p?utm=qr#top"},
+		{"https://a.com/", url.Values{"b": {"2"}, "a": {"x y"}}, "https://a.com/?a=x+y&b=2"},
+		{"not a url", params, "not a url"},
+	}
+
+	for _, tt := range tests {
+		if got := appendQuery(tt.rawURL, tt.params); got != tt.want {
+			t.Errorf("appendQuery(%q, %v) = %q, want %q", tt.rawURL, tt.params, got, tt.want)
+		}
+	}
+}
+
+// Lossy webp is deferred until an encoder supports it, the toggle is rejected instead of ignored
+func TestRunRejectsLossyWebP(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	var stdout, stderr bytes.Buffer
+	args := []string{"-u", "https://example.com", "-f", "webp", "-lossless=false", "-o", "-"}
+	if code := run(args, &stdout, &stderr); code != errCodeCommandLineUsageError {
+		t.Fatalf("exit code %d, want %d", code, errCodeCommandLineUsageError)
+	}
+	if !strings.Contains(stderr.String(), "lossy webp not supported") {
+		t.Errorf("unclear error: %s", stderr.String())
+	}
+}
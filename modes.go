@@ -0,0 +1,41 @@
+package main
+
+import "strings"
+
+// payloadMode is a structured payload selected by its flag instead of -u, registered modes are
+// dispatched by run and listed in usage examples
+type payloadMode struct {
+	// flag is the name of the flag selecting the mode
+	flag string
+	// example is the arguments of usage example
+	example string
+	// active reports whether the mode was selected on the command line
+	active func() bool
+	// payload builds encoded content and name used in auto-generated filenames
+	payload func() (content, name string, err error)
+	// errCode is the exit code of payload errors
+	errCode int
+}
+
+// activeModes returns modes selected on the command line
+func activeModes(modes []payloadMode) []payloadMode {
+	var active []payloadMode
+	for _, mode := range modes {
+		if mode.active() {
+			active = append(active, mode)
+		}
+	}
+	return active
+}
+
+// modeFlagList lists mode flags for messages, e.g. "-wifi, -vcard and -t"
+func modeFlagList(modes []payloadMode) string {
+	names := make([]string, 0, len(modes))
+	for _, mode := range modes {
+		names = append(names, "-"+mode.flag)
+	}
+	if len(names) < 2 {
+		return strings.Join(names, "")
+	}
+	return strings.Join(names[:len(names)-1], ", ") + " and " + names[len(names)-1]
+}
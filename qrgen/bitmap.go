@@ -0,0 +1,148 @@
+package qrgen
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+
+	"github.com/skip2/go-qrcode"
+	"golang.org/x/image/vector"
+)
+
+// Width of the quiet zone in modules added by the qrcode library
+const libraryBorder = 4
+
+// moduleBitmap returns QR code modules surrounded by quiet zone of border modules
+func moduleBitmap(qr *qrcode.QRCode, border int) [][]bool {
+	bitmap := qr.Bitmap()
+
+	// Strip quiet zone added by the library
+	if !qr.DisableBorder {
+		bitmap = bitmap[libraryBorder : len(bitmap)-libraryBorder]
+		for y := range bitmap {
+			bitmap[y] = bitmap[y][libraryBorder : len(bitmap[y])-libraryBorder]
+		}
+	}
+
+	dim := len(bitmap) + 2*border
+	result := make([][]bool, dim)
+	for y := range result {
+		result[y] = make([]bool, dim)
+	}
+	for y, row := range bitmap {
+		copy(result[y+border][border:], row)
+	}
+
+	return result
+}
+
+// isQuietZone checks whether module of bitmap lies in the quiet zone of border modules
+func isQuietZone(x, y, border, dim int) bool {
+	return x < border || y < border || x >= dim-border || y >= dim-border
+}
+
+// Width of finder pattern in modules
+const finderSize = 7
+
+// isFinderModule checks whether module of bitmap with quiet zone of border modules belongs to one
+// of three corner finder patterns
+func isFinderModule(x, y, border, dim int) bool {
+	x, y, size := x-border, y-border, dim-2*border
+	if x < 0 || y < 0 || x >= size || y >= size {
+		return false
+	}
+	left, right := x < finderSize, x >= size-finderSize
+	top, bottom := y < finderSize, y >= size-finderSize
+	return (top && (left || right)) || (bottom && left)
+}
+
+// renderBitmap renders modules as size x size image, each pixel is mapped to the nearest module
+// like qrcode.Image does. Size is increased to one pixel per module when too small. Quiet zone of
+// border modules is filled with borderColor unless it is nil.
+func renderBitmap(bitmap [][]bool, size int, fg, bg color.Color, border int, borderColor color.Color) *image.Paletted {
+	dim := len(bitmap)
+	if size < dim {
+		size = dim
+	}
+
+	palette := color.Palette{bg, fg}
+	if borderColor != nil {
+		palette = append(palette, borderColor)
+	}
+	img := image.NewPaletted(image.Rect(0, 0, size, size), palette)
+	modulesPerPixel := float64(dim) / float64(size)
+	for y := 0; y < size; y++ {
+		y2 := int(float64(y) * modulesPerPixel)
+		for x := 0; x < size; x++ {
+			x2 := int(float64(x) * modulesPerPixel)
+			switch {
+			case bitmap[y2][x2]:
+				img.Pix[img.PixOffset(x, y)] = 1
+			case borderColor != nil && isQuietZone(x2, y2, border, dim):
+				img.Pix[img.PixOffset(x, y)] = 2
+			}
+		}
+	}
+
+	return img
+}
+
+// Distance of cubic Bézier control points from the corner approximating quarter circle of radius 1
+const bezierCircle = 0.5523
+
+// renderRounded renders modules as size x size image like renderBitmap, but draws every dark
+// module as anti-aliased square with corners rounded by radius of module size. Finder patterns
+// stay square like with svg circle shape, scanners locate the code by them.
+func renderRounded(bitmap [][]bool, size int, fg, bg color.Color, border int, borderColor color.Color, radius float64) *image.RGBA {
+	dim := len(bitmap)
+	if size < dim {
+		size = dim
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	draw.Draw(img, img.Bounds(), image.NewUniform(bg), image.Point{}, draw.Src)
+	if borderColor != nil {
+		modulesPerPixel := float64(dim) / float64(size)
+		for y := 0; y < size; y++ {
+			for x := 0; x < size; x++ {
+				if isQuietZone(int(float64(x)*modulesPerPixel), int(float64(y)*modulesPerPixel), border, dim) {
+					img.Set(x, y, borderColor)
+				}
+			}
+		}
+	}
+
+	// All modules form one path, so edges of neighbours meet without seams
+	unit := float32(size) / float32(dim)
+	rasterizer := vector.NewRasterizer(size, size)
+	for y, row := range bitmap {
+		for x, dark := range row {
+			if !dark {
+				continue
+			}
+			corner := float32(radius) * unit
+			if isFinderModule(x, y, border, dim) {
+				corner = 0
+			}
+			addRoundedRect(rasterizer, float32(x)*unit, float32(y)*unit, unit, unit, corner)
+		}
+	}
+	rasterizer.Draw(img, img.Bounds(), image.NewUniform(fg), image.Point{})
+
+	return img
+}
+
+// addRoundedRect adds rectangle path with top left corner at x, y and corners rounded by radius
+func addRoundedRect(r *vector.Rasterizer, x, y, width, height, radius float32) {
+	c := radius * bezierCircle
+	r.MoveTo(x+radius, y)
+	r.LineTo(x+width-radius, y)
+	r.CubeTo(x+width-radius+c, y, x+width, y+radius-c, x+width, y+radius)
+	r.LineTo(x+width, y+height-radius)
+	r.CubeTo(x+width, y+height-radius+c, x+width-radius+c, y+height, x+width-radius, y+height)
+	r.LineTo(x+radius, y+height)
+	r.CubeTo(x+radius-c, y+height, x, y+height-radius+c, x, y+height-radius)
+	r.LineTo(x, y+radius)
+	r.CubeTo(x, y+radius-c, x+radius-c, y, x+radius, y)
+	r.ClosePath()
+}
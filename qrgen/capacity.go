@@ -0,0 +1,98 @@
+package qrgen
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/skip2/go-qrcode"
+)
+
+// Encoding modes of QR code content
+const (
+	ModeNumeric      = "numeric"
+	ModeAlphanumeric = "alphanumeric"
+	ModeByte         = "byte"
+)
+
+// Characters of alphanumeric mode besides digits and upper case letters
+const alphanumericSymbols = " $%*+-./:"
+
+// Repeated character of each mode and capacity of the mode in the largest QR code (version 40, level L)
+var modeSamples = map[string]struct {
+	char string
+	max  int
+}{
+	ModeNumeric:      {"1", 7089},
+	ModeAlphanumeric: {"A", 4296},
+	ModeByte:         {"a", MaxBinaryBytes},
+}
+
+// Capacity describes how many characters of the content encoding mode fit into the QR version at
+// every correction level
+type Capacity struct {
+	Mode string
+	// Version is the version content is encoded in, the forced or largest version when it does not fit
+	Version int
+	Fits    bool
+	// Levels holds capacity in characters at L, M, Q and H in the order of LevelNames
+	Levels []int
+}
+
+// PayloadMode returns the most compact mode which encodes every character of content
+func PayloadMode(content string) string {
+	mode := ModeNumeric
+	for _, r := range content {
+		switch {
+		case r >= '0' && r <= '9':
+		case r >= 'A' && r <= 'Z' || strings.ContainsRune(alphanumericSymbols, r):
+			mode = ModeAlphanumeric
+		default:
+			return ModeByte
+		}
+	}
+	return mode
+}
+
+// PayloadCapacity validates options, encodes content at the selected level and returns capacity
+// of its mode in the resulting version. Content which does not fit is reported with the forced or
+// largest version instead of an error.
+func PayloadCapacity(opts Options) (Capacity, error) {
+	if err := opts.Validate(); err != nil {
+		return Capacity{}, err
+	}
+
+	capacity := Capacity{Mode: PayloadMode(opts.Content), Version: opts.Version, Fits: true}
+	qr, err := newQRCode(opts)
+	switch {
+	case err == nil:
+		capacity.Version = qr.VersionNumber
+	case errors.Is(err, ErrCapacityExceeded):
+		capacity.Fits = false
+		if capacity.Version == 0 {
+			capacity.Version = MaxVersion
+		}
+	default:
+		return Capacity{}, err
+	}
+
+	for _, level := range []qrcode.RecoveryLevel{qrcode.Low, qrcode.Medium, qrcode.High, qrcode.Highest} {
+		capacity.Levels = append(capacity.Levels, modeCapacity(capacity.Mode, capacity.Version, level))
+	}
+	return capacity, nil
+}
+
+// modeCapacity finds the longest text of mode characters which fits into version at level, or into
+// the largest version when version is zero
+func modeCapacity(mode string, version int, level qrcode.RecoveryLevel) int {
+	sample := modeSamples[mode]
+	low, high := 0, sample.max
+	for low < high {
+		n := (low + high + 1) / 2
+		if _, err := encodeContent(strings.Repeat(sample.char, n), version, level); err == nil {
+			low = n
+		} else {
+			high = n - 1
+		}
+	}
+	return low
+}
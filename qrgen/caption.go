@@ -0,0 +1,95 @@
+package qrgen
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+	"sync"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// Limits and default of caption font height in pixels
+const (
+	DefaultCaptionSize = 16
+	MinCaptionSize     = 6
+	MaxCaptionSize     = 200
+)
+
+// Caption font parsed on first use, embedded font is known to be valid
+var captionFont = sync.OnceValue(func() *opentype.Font {
+	f, err := opentype.Parse(goregular.TTF)
+	if err != nil {
+		panic(err)
+	}
+	return f
+})
+
+// captionHeight returns height of caption area below QR code, one line with half line of padding
+func captionHeight(fontSize int) int {
+	return fontSize * 3 / 2
+}
+
+// addCaption draws image on canvas extended with caption area of bg color and writes text
+// centered in it. Font is shrunk when text does not fit into image width.
+func addCaption(img image.Image, text string, fontSize int, fg, bg color.Color) *image.RGBA {
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	areaHeight := captionHeight(fontSize)
+
+	canvas := image.NewRGBA(image.Rect(0, 0, width, height+areaHeight))
+	draw.Draw(canvas, canvas.Bounds(), image.NewUniform(bg), image.Point{}, draw.Src)
+	draw.Draw(canvas, img.Bounds(), img, img.Bounds().Min, draw.Src)
+	drawCenteredText(canvas, image.Rect(0, height, width, height+areaHeight), text, fontSize, fg)
+
+	return canvas
+}
+
+// drawCenteredText writes one line of text centered in area. Font is shrunk when text does not fit
+// into area width.
+func drawCenteredText(dst draw.Image, area image.Rectangle, text string, fontSize int, fg color.Color) {
+	width := area.Dx()
+	face := captionFace(fontSize)
+	textWidth := font.MeasureString(face, text).Ceil()
+	if margin := fontSize / 2; textWidth > width-2*margin && fontSize > MinCaptionSize {
+		fontSize = max(fontSize*(width-2*margin)/textWidth, MinCaptionSize)
+		face = captionFace(fontSize)
+		textWidth = font.MeasureString(face, text).Ceil()
+	}
+
+	// Center the line vertically between ascent and descent
+	metrics := face.Metrics()
+	baseline := area.Min.Y + (area.Dy()+metrics.Ascent.Ceil()-metrics.Descent.Ceil())/2
+	drawer := font.Drawer{
+		Dst:  dst,
+		Src:  image.NewUniform(fg),
+		Face: face,
+		Dot:  fixed.P(area.Min.X+(width-textWidth)/2, baseline),
+	}
+	drawer.DrawString(text)
+}
+
+// captionFace returns caption font face with height of fontSize pixels
+func captionFace(fontSize int) font.Face {
+	face, err := opentype.NewFace(captionFont(), &opentype.FaceOptions{
+		Size:    float64(fontSize),
+		DPI:     72,
+		Hinting: font.HintingFull,
+	})
+	if err != nil {
+		panic(err)
+	}
+	return face
+}
+
+// writeSVGCaption writes caption text element centered in caption area below QR code of dim pixels
+func writeSVGCaption(builder *strings.Builder, text string, fontSize int, fill string, dim int) {
+	fmt.Fprintf(builder, "<text x=\"%d\" y=\"%d\" font-family=\"sans-serif\" font-size=\"%d\" text-anchor=\"middle\" dominant-baseline=\"middle\" fill=\"%s\">",
+		dim/2, dim+captionHeight(fontSize)/2, fontSize, fill)
+	builder.WriteString(xmlEscape(text))
+	builder.WriteString("</text>\n")
+}
@@ -0,0 +1,97 @@
+package qrgen
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// TransparentColor is the svg and png background value which disables the background
+const TransparentColor = "none"
+
+// ParseHexColor converts hex color string (#rgb or #rrggbb, leading # is optional) to color
+func ParseHexColor(input string) (color.RGBA, error) {
+	hex := strings.TrimPrefix(input, "#")
+
+	// Expand short form, e.g. "1af" to "11aaff"
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) != 6 {
+		return color.RGBA{}, fmt.Errorf("invalid hex color '%s' (expected #rgb or #rrggbb)", input)
+	}
+
+	value, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return color.RGBA{}, fmt.Errorf("invalid hex color '%s' (expected #rgb or #rrggbb)", input)
+	}
+
+	return color.RGBA{R: uint8(value >> 16), G: uint8(value >> 8), B: uint8(value), A: 0xff}, nil
+}
+
+// MinContrastRatio is the contrast ratio of foreground and background below which codes may not scan
+const MinContrastRatio = 3.0
+
+// ContrastRatio returns WCAG contrast ratio of two colors, from 1 for equal colors to 21 for
+// black and white
+func ContrastRatio(a, b color.Color) float64 {
+	la, lb := relativeLuminance(a), relativeLuminance(b)
+	return (max(la, lb) + 0.05) / (min(la, lb) + 0.05)
+}
+
+// relativeLuminance returns WCAG relative luminance of color
+func relativeLuminance(c color.Color) float64 {
+	r, g, b, _ := c.RGBA()
+	linear := func(v uint32) float64 {
+		s := float64(v) / 0xffff
+		if s <= 0.03928 {
+			return s / 12.92
+		}
+		return math.Pow((s+0.055)/1.055, 2.4)
+	}
+	return 0.2126*linear(r) + 0.7152*linear(g) + 0.0722*linear(b)
+}
+
+// HexColor formats color as #rrggbb string
+func HexColor(c color.Color) string {
+	r, g, b, _ := c.RGBA()
+	return fmt.Sprintf("#%02x%02x%02x", r>>8, g>>8, b>>8)
+}
+
+// Gradient is a two color linear gradient, Angle in degrees rotates it clockwise from left to right
+type Gradient struct {
+	From  color.RGBA
+	To    color.RGBA
+	Angle float64
+}
+
+// ParseGradient converts "from,to" or "from,to@angle" spec of two hex colors to gradient
+func ParseGradient(spec string) (Gradient, error) {
+	var gradient Gradient
+
+	colors := spec
+	if i := strings.LastIndex(spec, "@"); i >= 0 {
+		angle, err := strconv.ParseFloat(spec[i+1:], 64)
+		if err != nil {
+			return gradient, fmt.Errorf("invalid gradient angle '%s'", spec[i+1:])
+		}
+		colors, gradient.Angle = spec[:i], angle
+	}
+
+	parts := strings.Split(colors, ",")
+	if len(parts) != 2 {
+		return gradient, fmt.Errorf("invalid gradient '%s' (expected from,to or from,to@angle)", spec)
+	}
+
+	var err error
+	if gradient.From, err = ParseHexColor(strings.TrimSpace(parts[0])); err != nil {
+		return gradient, err
+	}
+	if gradient.To, err = ParseHexColor(strings.TrimSpace(parts[1])); err != nil {
+		return gradient, err
+	}
+
+	return gradient, nil
+}
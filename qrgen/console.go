@@ -0,0 +1,96 @@
+package qrgen
+
+import (
+	"strings"
+
+	"github.com/skip2/go-qrcode"
+)
+
+// ANSI escape sequences for console cells
+const (
+	ansiBlack = "\x1b[40m"
+	ansiWhite = "\x1b[47m"
+	ansiReset = "\x1b[0m"
+)
+
+// HalfBlockString renders QR code with quiet zone of border modules, two module rows per text line
+// using Unicode half blocks. Light modules are drawn as blocks like qrcode.ToSmallString does,
+// so the code scans from terminals with dark background; invert draws dark modules instead.
+func HalfBlockString(qr *qrcode.QRCode, border int, invert bool) string {
+	bitmap := moduleBitmap(qr, border)
+	var builder strings.Builder
+
+	for y := 0; y < len(bitmap); y += 2 {
+		for x := range bitmap[y] {
+			top := bitmap[y][x] == invert
+			// Last line of odd number of rows has no bottom half
+			bottom := y+1 < len(bitmap) && bitmap[y+1][x] == invert
+			switch {
+			case top && bottom:
+				builder.WriteString("█")
+			case top:
+				builder.WriteString("▀")
+			case bottom:
+				builder.WriteString("▄")
+			default:
+				builder.WriteString(" ")
+			}
+		}
+		builder.WriteString("\n")
+	}
+
+	return builder.String()
+}
+
+// FullString renders QR code with quiet zone of border modules as two full blocks per module, so
+// modules stay square in fonts with cells twice as high as wide. Light modules are blocks like with
+// HalfBlockString, invert draws dark modules instead.
+func FullString(qr *qrcode.QRCode, border int, invert bool) string {
+	return cellString(qr, border, invert, "██")
+}
+
+// ASCIIString is FullString drawing ## instead of blocks, for terminals and fonts without block
+// characters
+func ASCIIString(qr *qrcode.QRCode, border int, invert bool) string {
+	return cellString(qr, border, invert, "##")
+}
+
+// cellString renders light modules, or dark ones with invert, as cell and the others as two spaces
+func cellString(qr *qrcode.QRCode, border int, invert bool, cell string) string {
+	bitmap := moduleBitmap(qr, border)
+	var builder strings.Builder
+
+	for _, row := range bitmap {
+		for _, dark := range row {
+			if dark == invert {
+				builder.WriteString(cell)
+			} else {
+				builder.WriteString("  ")
+			}
+		}
+		builder.WriteString("\n")
+	}
+
+	return builder.String()
+}
+
+// ANSIString renders QR code with quiet zone of border modules as ANSI background colored cells,
+// two spaces per module so modules stay square. Dark modules are black unless invert is set.
+func ANSIString(qr *qrcode.QRCode, border int, invert bool) string {
+	bitmap := moduleBitmap(qr, border)
+	var builder strings.Builder
+
+	for _, row := range bitmap {
+		for _, dark := range row {
+			if dark != invert {
+				builder.WriteString(ansiBlack)
+			} else {
+				builder.WriteString(ansiWhite)
+			}
+			builder.WriteString("  ")
+		}
+		builder.WriteString(ansiReset + "\n")
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,82 @@
+package qrgen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+
+	"github.com/makiuchi-d/gozxing"
+	zxingqr "github.com/makiuchi-d/gozxing/qrcode"
+
+	// Decoder of webp output, png, jpeg, gif and tiff decoders are registered by image.go imports
+	_ "golang.org/x/image/webp"
+)
+
+// Decode reads QR code from png, jpeg, gif, webp or tiff image data and returns its content
+func Decode(data []byte) (string, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("cannot read image: %w", err)
+	}
+	result, err := decodeImage(img)
+	if err != nil {
+		return "", err
+	}
+	return result.GetText(), nil
+}
+
+// decodeImage finds and decodes QR code in image, light modules on dark background are tried
+// when the regular code is not found
+func decodeImage(img image.Image) (*gozxing.Result, error) {
+	source := gozxing.NewLuminanceSourceFromImage(img)
+	hints := map[gozxing.DecodeHintType]interface{}{gozxing.DecodeHintType_TRY_HARDER: true}
+
+	for _, candidate := range []gozxing.LuminanceSource{source, source.Invert()} {
+		bitmap, err := gozxing.NewBinaryBitmap(gozxing.NewHybridBinarizer(candidate))
+		if err != nil {
+			return nil, err
+		}
+		if result, err := zxingqr.NewQRCodeReader().Decode(bitmap, hints); err == nil {
+			return result, nil
+		}
+	}
+	// Decoder errors name its internal exceptions, which tell nothing about the image
+	return nil, errors.New("no readable QR code found in image")
+}
+
+// Verify decodes generated QR code and checks that it holds opts.Content, returns the decoded text.
+// Raster output is decoded from its file data, other output from raster rendering of the same
+// modules and colors, so svg Shape, EyeStyle and Gradient are not verified.
+func Verify(result Result, opts Options) (string, error) {
+	var img image.Image
+	if isRasterFormat(opts.Format) {
+		decoded, _, err := image.Decode(bytes.NewReader(result.Data))
+		if err != nil {
+			return "", fmt.Errorf("cannot read %s output: %w", opts.Format, err)
+		}
+		img = decoded
+	} else {
+		if opts.Scale > 0 {
+			opts.Size = ScaledSize(result.QRCode, opts.Scale, opts.Border)
+		}
+		img = RenderImage(result.QRCode, opts)
+	}
+
+	decoded, err := decodeImage(img)
+	if err != nil {
+		return "", err
+	}
+	text := decoded.GetText()
+	if text == opts.Content || bytes.Equal(byteSegments(decoded), []byte(opts.Content)) {
+		return text, nil
+	}
+	return text, fmt.Errorf("decoded content %q does not match payload", text)
+}
+
+// byteSegments joins raw bytes of byte mode segments, which hold binary payload that decoder text
+// conversion could alter
+func byteSegments(result *gozxing.Result) []byte {
+	segments, _ := result.GetResultMetadata()[gozxing.ResultMetadataType_BYTE_SEGMENTS].([][]byte)
+	return bytes.Join(segments, nil)
+}
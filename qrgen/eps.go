@@ -0,0 +1,41 @@
+package qrgen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/skip2/go-qrcode"
+)
+
+// GenerateEPS generates encapsulated PostScript image of size x size points with horizontal runs of
+// dark modules drawn as filled rectangles and quiet zone of border modules
+func GenerateEPS(qr *qrcode.QRCode, size, border int) string {
+	var builder strings.Builder
+
+	bitmap := moduleBitmap(qr, border)
+	dim := len(bitmap)
+	unit := float64(size) / float64(dim)
+
+	builder.WriteString("%!PS-Adobe-3.0 EPSF-3.0\n")
+	fmt.Fprintf(&builder, "%%%%BoundingBox: 0 0 %d %d\n", size, size)
+	builder.WriteString("%%Creator: qr-generator\n%%Pages: 1\n%%EndComments\n")
+
+	// PostScript origin is the bottom left corner, same as in pdf
+	fmt.Fprintf(&builder, "%s setrgbcolor\n0 0 %d %d rectfill\n", pdfColor(qr.BackgroundColor), size, size)
+	fmt.Fprintf(&builder, "%s setrgbcolor\n", pdfColor(qr.ForegroundColor))
+	for y := 0; y < dim; y++ {
+		for x := 0; x < dim; x++ {
+			if !bitmap[y][x] {
+				continue
+			}
+			start := x
+			for x < dim && bitmap[y][x] {
+				x++
+			}
+			fmt.Fprintf(&builder, "%.3f %.3f %.3f %.3f rectfill\n", float64(start)*unit, float64(dim-y-1)*unit, float64(x-start)*unit, unit)
+		}
+	}
+	builder.WriteString("showpage\n%%EOF\n")
+
+	return builder.String()
+}
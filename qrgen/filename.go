@@ -0,0 +1,33 @@
+package qrgen
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Store regular expression for reuse
+var filenameSanitizer = regexp.MustCompile(`[^a-zA-Z0-9]+`)
+
+// SanitizeFilename clears string from characters unsafe for filenames, every run of characters
+// other than Unicode letters, digits and combining marks becomes one underscore. Path separators,
+// control characters and punctuation are replaced.
+func SanitizeFilename(input string) string {
+	var builder strings.Builder
+	replaced := false
+	for _, r := range input {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r) {
+			builder.WriteRune(r)
+			replaced = false
+		} else if !replaced {
+			builder.WriteByte('_')
+			replaced = true
+		}
+	}
+	return builder.String()
+}
+
+// SanitizeASCIIFilename is SanitizeFilename which keeps only ASCII letters and digits
+func SanitizeASCIIFilename(input string) string {
+	return filenameSanitizer.ReplaceAllString(input, "_")
+}
@@ -0,0 +1,43 @@
+package qrgen
+
+import "testing"
+
+func TestSanitizeFilename(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+		ascii string
+	}{
+		{"ascii", "example123", "example123", "example123"},
+		{"unicode letters", "привет世界", "привет世界", "_"},
+		{"combining marks", "cafe\u0301", "cafe\u0301", "cafe_"},
+		{"path separators", "a/b\\c", "a_b_c", "a_b_c"},
+		{"parent directory", "../etc/passwd", "_etc_passwd", "_etc_passwd"},
+		{"control characters", "a\tb\nc\x00d", "a_b_c_d", "a_b_c_d"},
+		{"runs collapse", "a://??b", "a_b", "a_b"},
+		{"url", "https://a.com/x?y=1", "https_a_com_x_y_1", "https_a_com_x_y_1"},
+		{"unicode digits", "٣٤", "٣٤", "_"},
+		{"empty", "", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SanitizeFilename(tt.input); got != tt.want {
+				t.Errorf("SanitizeFilename(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+			if got := SanitizeASCIIFilename(tt.input); got != tt.ascii {
+				t.Errorf("SanitizeASCIIFilename(%q) = %q, want %q", tt.input, got, tt.ascii)
+			}
+		})
+	}
+}
+
+// Different payloads can sanitize to the same name, callers add hash of payload to tell them apart
+func TestSanitizeFilenameCollision(t *testing.T) {
+	for _, sanitize := range []func(string) string{SanitizeFilename, SanitizeASCIIFilename} {
+		if a, b := sanitize("a.com/x"), sanitize("a.com-x"); a != b || a != "a_com_x" {
+			t.Errorf("got %q and %q, want both a_com_x", a, b)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package qrgen
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/skip2/go-qrcode"
+)
+
+// Encoder renders encoded QR code to file contents of one output format
+type Encoder interface {
+	Encode(qr *qrcode.QRCode, opts Options) ([]byte, error)
+	// Extension is the file extension without dot
+	Extension() string
+	// MIMEType is the content type used in data URIs and HTTP responses
+	MIMEType() string
+}
+
+// Registry of output formats by name
+var encoders = map[string]Encoder{}
+
+// RegisterFormat adds output format, or replaces encoder of existing one. Formats are read without
+// locking, so registration belongs to program initialization.
+func RegisterFormat(name string, encoder Encoder) {
+	encoders[name] = encoder
+}
+
+// encoderFunc Encoder of a function with fixed extension and MIME type
+type encoderFunc struct {
+	encode    func(qr *qrcode.QRCode, opts Options) ([]byte, error)
+	extension string
+	mimeType  string
+}
+
+func (e encoderFunc) Encode(qr *qrcode.QRCode, opts Options) ([]byte, error) {
+	return e.encode(qr, opts)
+}
+
+func (e encoderFunc) Extension() string {
+	return e.extension
+}
+
+func (e encoderFunc) MIMEType() string {
+	return e.mimeType
+}
+
+// Built-in formats
+func init() {
+	raster := func(qr *qrcode.QRCode, opts Options) ([]byte, error) {
+		img := RenderImage(qr, opts)
+		if opts.CanvasWidth > 0 {
+			var err error
+			if img, err = placeOnCanvas(img, opts.CanvasWidth, opts.CanvasHeight, qr.BackgroundColor); err != nil {
+				return nil, err
+			}
+		}
+		return encodeImage(img, opts)
+	}
+	RegisterFormat("png", encoderFunc{raster, "png", "image/png"})
+	RegisterFormat("jpeg", encoderFunc{raster, "jpeg", "image/jpeg"})
+	RegisterFormat("gif", encoderFunc{raster, "gif", "image/gif"})
+	RegisterFormat("webp", encoderFunc{raster, "webp", "image/webp"})
+	RegisterFormat("tiff", encoderFunc{raster, "tiff", "image/tiff"})
+	RegisterFormat("svg", encoderFunc{encodeSVG, "svg", "image/svg+xml"})
+	RegisterFormat("pdf", encoderFunc{func(qr *qrcode.QRCode, opts Options) ([]byte, error) {
+		return GeneratePDF(qr, pointSize(opts), opts.Border)
+	}, "pdf", "application/pdf"})
+	RegisterFormat("eps", encoderFunc{func(qr *qrcode.QRCode, opts Options) ([]byte, error) {
+		return []byte(GenerateEPS(qr, pointSize(opts), opts.Border)), nil
+	}, "eps", "application/postscript"})
+	RegisterFormat("matrix", encoderFunc{func(qr *qrcode.QRCode, opts Options) ([]byte, error) {
+		return []byte(GenerateMatrix(qr, opts.Border, opts.ASCII)), nil
+	}, "txt", "text/plain"})
+	RegisterFormat("json", encoderFunc{func(qr *qrcode.QRCode, opts Options) ([]byte, error) {
+		return GenerateMatrixJSON(qr, opts.Border)
+	}, "json", "application/json"})
+	// Console renderings, light modules are blocks like the preview unless the code is negative
+	RegisterFormat("txt", encoderFunc{func(qr *qrcode.QRCode, opts Options) ([]byte, error) {
+		return []byte(HalfBlockString(qr, opts.Border, opts.Negative)), nil
+	}, "txt", "text/plain;charset=utf-8"})
+	RegisterFormat("ansi", encoderFunc{func(qr *qrcode.QRCode, opts Options) ([]byte, error) {
+		return []byte(ANSIString(qr, opts.Border, opts.Negative)), nil
+	}, "ans", "text/plain;charset=utf-8"})
+}
+
+// IsValidFormat checks whether specified format is registered
+func IsValidFormat(format string) bool {
+	_, ok := encoders[format]
+	return ok
+}
+
+// ParseFormatList splits comma-separated list of formats and checks every item,
+// duplicates are dropped keeping the order
+func ParseFormatList(list string) ([]string, error) {
+	var formats []string
+	seen := make(map[string]bool)
+	// Formats sharing extension would write the same file
+	extensions := make(map[string]string)
+	for _, format := range strings.Split(list, ",") {
+		format = strings.TrimSpace(format)
+		if !IsValidFormat(format) {
+			return nil, fmt.Errorf("unsupported file format '%s', supported formats are %s", format, FormatList())
+		}
+		if seen[format] {
+			continue
+		}
+		ext := Extension(format)
+		if other, ok := extensions[ext]; ok {
+			return nil, fmt.Errorf("formats '%s' and '%s' both write .%s files, select one of them", other, format, ext)
+		}
+		seen[format], extensions[ext] = true, format
+		formats = append(formats, format)
+	}
+	return formats, nil
+}
+
+// Formats returns sorted names of supported formats
+func Formats() []string {
+	formats := make([]string, 0, len(encoders))
+	for format := range encoders {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// FormatList lists supported formats for messages
+func FormatList() string {
+	return strings.Join(Formats(), ", ")
+}
+
+// MIMEType returns MIME type of the format, empty for unknown format
+func MIMEType(format string) string {
+	if encoder, ok := encoders[format]; ok {
+		return encoder.MIMEType()
+	}
+	return ""
+}
+
+// Extension returns file extension of the format without dot, the format name for unknown format
+func Extension(format string) string {
+	if encoder, ok := encoders[format]; ok {
+		return encoder.Extension()
+	}
+	return format
+}
+
+// DataURI encodes file contents as base64 data URI
+func DataURI(data []byte, format string) string {
+	return fmt.Sprintf("data:%s;base64,%s", MIMEType(format), base64.StdEncoding.EncodeToString(data))
+}
+
+// isRasterFormat checks whether format is rendered from raster image
+func isRasterFormat(format string) bool {
+	return format == "png" || format == "jpeg" || format == "gif" || format == "webp" || format == "tiff"
+}
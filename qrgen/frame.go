@@ -0,0 +1,60 @@
+package qrgen
+
+import (
+	"image"
+	"image/color"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/vector"
+)
+
+// DefaultFrameText is the banner label of frame
+const DefaultFrameText = "SCAN ME"
+
+// addFrame draws image on canvas inside rounded border of fg color which widens at the bottom into
+// banner bar with text of bg color. Empty text draws the border only. Image keeps its quiet zone,
+// canvas grows by the frame and space outside the rounded corners stays bg.
+func addFrame(img image.Image, text string, fg, bg color.Color) *image.RGBA {
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	stroke := max(width/50, 2)
+	// Inner corner radius and gap which keeps square image corners inside the rounded area
+	radius := max(width/25, 2)
+	gap := radius / 2
+
+	fontSize := max(width/12, MinCaptionSize)
+	banner := stroke
+	if len(text) > 0 {
+		banner = captionHeight(fontSize)
+	}
+	innerWidth, innerHeight := width+2*gap, height+2*gap
+	canvasWidth, canvasHeight := innerWidth+2*stroke, innerHeight+stroke+banner
+
+	canvas := image.NewRGBA(image.Rect(0, 0, canvasWidth, canvasHeight))
+	draw.Draw(canvas, canvas.Bounds(), image.NewUniform(bg), image.Point{}, draw.Src)
+
+	// Frame is the outer rounded area minus the inner one, so transparent bg shows through both
+	outer := roundedMask(canvas.Bounds(), 0, 0, float32(canvasWidth), float32(canvasHeight), float32(radius+stroke))
+	inner := roundedMask(canvas.Bounds(), float32(stroke), float32(stroke), float32(innerWidth), float32(innerHeight), float32(radius))
+	for i := range outer.Pix {
+		outer.Pix[i] = uint8(int(outer.Pix[i]) * (255 - int(inner.Pix[i])) / 255)
+	}
+	draw.DrawMask(canvas, canvas.Bounds(), image.NewUniform(fg), image.Point{}, outer, image.Point{}, draw.Over)
+
+	origin := image.Pt(stroke+gap, stroke+gap)
+	draw.Draw(canvas, image.Rectangle{origin, origin.Add(img.Bounds().Size())}, img, img.Bounds().Min, draw.Src)
+
+	if len(text) > 0 {
+		area := image.Rect(stroke, stroke+innerHeight, canvasWidth-stroke, canvasHeight)
+		drawCenteredText(canvas, area, text, fontSize, bg)
+	}
+	return canvas
+}
+
+// roundedMask returns coverage of rounded rectangle within bounds
+func roundedMask(bounds image.Rectangle, x, y, width, height, radius float32) *image.Alpha {
+	mask := image.NewAlpha(bounds)
+	rasterizer := vector.NewRasterizer(bounds.Dx(), bounds.Dy())
+	addRoundedRect(rasterizer, x, y, width, height, radius)
+	rasterizer.Draw(mask, bounds, image.Opaque, image.Point{})
+	return mask
+}
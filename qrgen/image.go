@@ -0,0 +1,222 @@
+package qrgen
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"os"
+
+	"github.com/HugoSmits86/nativewebp"
+	"github.com/skip2/go-qrcode"
+	"golang.org/x/image/draw"
+	"golang.org/x/image/tiff"
+)
+
+// Compression types of tiff output
+const (
+	CompressDeflate = "deflate"
+	CompressNone    = "none"
+	// CompressLZW is recognized but not supported by the encoder
+	CompressLZW = "lzw"
+)
+
+// Compression levels of png output
+const (
+	PNGSpeed   = "speed"
+	PNGDefault = "default"
+	PNGBest    = "best"
+)
+
+// Encoder settings of png compression levels
+var pngLevels = map[string]png.CompressionLevel{
+	PNGSpeed:   png.BestSpeed,
+	PNGDefault: png.DefaultCompression,
+	PNGBest:    png.BestCompression,
+}
+
+// Logo size in percent of the image size
+const logoPercent = 20
+
+// RenderImage renders QR code as raster image with quiet zone of opts.Border modules over the
+// background image, logo composited over the center, caption below and frame around
+func RenderImage(qr *qrcode.QRCode, opts Options) image.Image {
+	var borderColor color.Color
+	if len(opts.BorderColor) > 0 {
+		borderColor, _ = ParseHexColor(opts.BorderColor)
+	}
+	// Light modules over background image stay transparent and show the photo
+	bg := qr.BackgroundColor
+	if opts.BackgroundImage != nil {
+		bg = color.Transparent
+	}
+	bitmap := moduleBitmap(qr, opts.Border)
+	var img image.Image = renderBitmap(bitmap, opts.Size, qr.ForegroundColor, bg, opts.Border, borderColor)
+	if opts.Radius > 0 {
+		img = renderRounded(bitmap, opts.Size, qr.ForegroundColor, bg, opts.Border, borderColor, opts.Radius)
+	}
+
+	if opts.BackgroundImage != nil {
+		canvas := image.NewRGBA(img.Bounds())
+		drawCover(canvas, opts.BackgroundImage)
+		draw.Draw(canvas, canvas.Bounds(), img, image.Point{}, draw.Over)
+		img = canvas
+	}
+
+	if opts.Logo != nil {
+		canvas := image.NewRGBA(img.Bounds())
+		draw.Draw(canvas, canvas.Bounds(), img, image.Point{}, draw.Src)
+		drawLogo(canvas, opts.Logo, qr.BackgroundColor)
+		img = canvas
+	}
+
+	if len(opts.Caption) > 0 {
+		img = addCaption(img, opts.Caption, opts.CaptionSize, qr.ForegroundColor, qr.BackgroundColor)
+	}
+
+	if opts.Frame {
+		img = addFrame(img, opts.FrameText, qr.ForegroundColor, qr.BackgroundColor)
+	}
+
+	return img
+}
+
+// placeOnCanvas centers image in width x height canvas filled with bg color
+func placeOnCanvas(img image.Image, width, height int, bg color.Color) (image.Image, error) {
+	bounds := img.Bounds()
+	if bounds.Dx() > width || bounds.Dy() > height {
+		return nil, canvasError(bounds.Dx(), bounds.Dy(), width, height)
+	}
+	canvas := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(canvas, canvas.Bounds(), image.NewUniform(bg), image.Point{}, draw.Src)
+	origin := image.Pt((width-bounds.Dx())/2, (height-bounds.Dy())/2)
+	draw.Draw(canvas, image.Rectangle{origin, origin.Add(bounds.Size())}, img, bounds.Min, draw.Src)
+	return canvas, nil
+}
+
+// canvasError reports image which is larger than canvas
+func canvasError(width, height, canvasWidth, canvasHeight int) error {
+	return fmt.Errorf("QR code image of %dx%d pixels does not fit into canvas of %dx%d", width, height, canvasWidth, canvasHeight)
+}
+
+// LoadLogo reads png or jpeg logo image
+func LoadLogo(path string) (image.Image, error) {
+	return loadImage(path, "logo")
+}
+
+// LoadBackgroundImage reads png or jpeg image drawn behind the modules
+func LoadBackgroundImage(path string) (image.Image, error) {
+	return loadImage(path, "background image")
+}
+
+// loadImage reads png or jpeg image, kind names its purpose in errors
+func loadImage(path, kind string) (image.Image, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode %s '%s': %v", kind, path, err)
+	}
+
+	return img, nil
+}
+
+// drawCover scales image to cover the whole canvas keeping its aspect ratio, the overflowing sides
+// are cropped evenly
+func drawCover(canvas *image.RGBA, img image.Image) {
+	bounds := img.Bounds()
+	width, height := canvas.Bounds().Dx(), canvas.Bounds().Dy()
+	crop := bounds
+	if bounds.Dx()*height > bounds.Dy()*width {
+		cropWidth := bounds.Dy() * width / height
+		crop.Min.X += (bounds.Dx() - cropWidth) / 2
+		crop.Max.X = crop.Min.X + cropWidth
+	} else {
+		cropHeight := bounds.Dx() * height / width
+		crop.Min.Y += (bounds.Dy() - cropHeight) / 2
+		crop.Max.Y = crop.Min.Y + cropHeight
+	}
+	draw.CatmullRom.Scale(canvas, canvas.Bounds(), img, crop, draw.Src, nil)
+}
+
+// drawLogo scales logo to logoPercent of the canvas and draws it in the center on a rounded padding box
+func drawLogo(canvas *image.RGBA, logo image.Image, padColor color.Color) {
+	size := canvas.Bounds().Dx()
+	side := size * logoPercent / 100
+
+	// Keep logo aspect ratio within side x side square
+	bounds := logo.Bounds()
+	width, height := side, side
+	if bounds.Dx() > bounds.Dy() {
+		height = side * bounds.Dy() / bounds.Dx()
+	} else {
+		width = side * bounds.Dx() / bounds.Dy()
+	}
+
+	target := image.Rect((size-width)/2, (size-height)/2, (size-width)/2+width, (size-height)/2+height)
+	padding := side / 10
+	fillRoundedRect(canvas, target.Inset(-padding), 2*padding, padColor)
+	draw.CatmullRom.Scale(canvas, target, logo, bounds, draw.Over, nil)
+}
+
+// fillRoundedRect fills rectangle with corners rounded by radius pixels
+func fillRoundedRect(img *image.RGBA, rect image.Rectangle, radius int, c color.Color) {
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			// Distance to the rectangle shrunk by radius, non-zero only in the corners
+			dx := max(rect.Min.X+radius-x, x-(rect.Max.X-1-radius), 0)
+			dy := max(rect.Min.Y+radius-y, y-(rect.Max.Y-1-radius), 0)
+			if dx*dx+dy*dy <= radius*radius {
+				img.Set(x, y, c)
+			}
+		}
+	}
+}
+
+// encodeImage encodes rendered QR code image with png, jpeg, gif, lossless webp or tiff encoder
+func encodeImage(img image.Image, opts Options) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+
+	switch opts.Format {
+	case "png":
+		level := png.BestCompression
+		if len(opts.PNGLevel) > 0 {
+			level = pngLevels[opts.PNGLevel]
+		}
+		err = writePNG(&buf, img, level)
+	case "jpeg":
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: opts.Quality})
+	case "gif":
+		err = gif.Encode(&buf, img, nil)
+	case "webp":
+		err = nativewebp.Encode(&buf, img, nil)
+	case "tiff":
+		compression := tiff.Deflate
+		if opts.Compression == CompressNone {
+			compression = tiff.Uncompressed
+		}
+		err = tiff.Encode(&buf, img, &tiff.Options{Compression: compression})
+	default:
+		err = fmt.Errorf("invalid image format '%s'", opts.Format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	data := buf.Bytes()
+	if opts.Millimeters > 0 && opts.Format == "png" {
+		data = addPNGResolution(data, opts.DPI)
+	}
+	if opts.Metadata && opts.Format == "png" {
+		data = addPNGMetadata(data, opts.Content, opts.Created)
+	}
+	return data, nil
+}
@@ -0,0 +1,57 @@
+package qrgen
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/skip2/go-qrcode"
+)
+
+// GenerateMatrix returns modules of QR code with quiet zone of border modules as text, one row per
+// line with 1 for dark and 0 for light modules, or # and space when ascii is set
+func GenerateMatrix(qr *qrcode.QRCode, border int, ascii bool) string {
+	dark, light := "1", "0"
+	if ascii {
+		dark, light = "#", " "
+	}
+
+	var builder strings.Builder
+	for _, row := range moduleBitmap(qr, border) {
+		for _, module := range row {
+			if module {
+				builder.WriteString(dark)
+			} else {
+				builder.WriteString(light)
+			}
+		}
+		builder.WriteString("\n")
+	}
+	return builder.String()
+}
+
+// matrixDocument is json output describing QR code modules
+type matrixDocument struct {
+	Version int    `json:"version"`
+	Level   string `json:"level"`
+	Border  int    `json:"border"`
+	// Dimension is the number of rows and columns of Modules including the quiet zone
+	Dimension int      `json:"dimension"`
+	Modules   [][]bool `json:"modules"`
+}
+
+// GenerateMatrixJSON returns json document with version, correction level and modules of QR code
+// with quiet zone of border modules, true modules are dark
+func GenerateMatrixJSON(qr *qrcode.QRCode, border int) ([]byte, error) {
+	modules := moduleBitmap(qr, border)
+	data, err := json.Marshal(matrixDocument{
+		Version:   qr.VersionNumber,
+		Level:     LevelName(qr.Level),
+		Border:    border,
+		Dimension: len(modules),
+		Modules:   modules,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
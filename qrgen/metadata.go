@@ -0,0 +1,96 @@
+package qrgen
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"math"
+	"time"
+	"unicode/utf8"
+)
+
+// End of IHDR chunk in png stream, the 8 byte signature followed by 13 bytes of header data
+// framed by length, type and crc
+const pngHeaderEnd = 8 + 4 + 4 + 13 + 4
+
+// Keywords of png text chunks
+const (
+	pngKeywordDescription = "Description"
+	pngKeywordCreated     = "Creation Time"
+	pngKeywordSoftware    = "Software"
+)
+
+// Software name written to png metadata
+const softwareName = "qr-generator"
+
+// addPNGMetadata inserts text chunks with payload, creation time and software name after the
+// header of png stream, zero created time is omitted
+func addPNGMetadata(data []byte, payload string, created time.Time) []byte {
+	var chunks bytes.Buffer
+	writePNGText(&chunks, pngKeywordDescription, payload)
+	if !created.IsZero() {
+		writePNGText(&chunks, pngKeywordCreated, created.Format(time.RFC3339))
+	}
+	writePNGText(&chunks, pngKeywordSoftware, softwareName)
+
+	return insertPNGChunks(data, chunks.Bytes())
+}
+
+// addPNGResolution inserts pHYs chunk recording dpi after the header of png stream
+func addPNGResolution(data []byte, dpi int) []byte {
+	var chunk bytes.Buffer
+	perMeter := uint32(math.Round(float64(dpi) / 0.0254))
+	var phys [9]byte
+	binary.BigEndian.PutUint32(phys[0:], perMeter)
+	binary.BigEndian.PutUint32(phys[4:], perMeter)
+	// Unit is the meter
+	phys[8] = 1
+	writePNGChunk(&chunk, "pHYs", phys[:])
+	return insertPNGChunks(data, chunk.Bytes())
+}
+
+// insertPNGChunks inserts encoded chunks after the header of png stream
+func insertPNGChunks(data, chunks []byte) []byte {
+	result := make([]byte, 0, len(data)+len(chunks))
+	result = append(result, data[:pngHeaderEnd]...)
+	result = append(result, chunks...)
+	return append(result, data[pngHeaderEnd:]...)
+}
+
+// writePNGText writes tEXt chunk, text which is not plain ASCII is written as uncompressed
+// UTF-8 iTXt chunk instead
+func writePNGText(buf *bytes.Buffer, keyword, text string) {
+	var data bytes.Buffer
+	data.WriteString(keyword)
+	data.WriteByte(0)
+	if isPNGASCII(text) {
+		data.WriteString(text)
+		writePNGChunk(buf, "tEXt", data.Bytes())
+		return
+	}
+	// No compression, empty language tag and translated keyword
+	data.Write([]byte{0, 0, 0, 0})
+	data.WriteString(text)
+	writePNGChunk(buf, "iTXt", data.Bytes())
+}
+
+// writePNGChunk frames chunk data with length, type and crc
+func writePNGChunk(buf *bytes.Buffer, kind string, data []byte) {
+	_ = binary.Write(buf, binary.BigEndian, uint32(len(data)))
+	crc := crc32.NewIEEE()
+	crc.Write([]byte(kind))
+	crc.Write(data)
+	buf.WriteString(kind)
+	buf.Write(data)
+	_ = binary.Write(buf, binary.BigEndian, crc.Sum32())
+}
+
+// isPNGASCII checks whether text is ASCII without NUL, which is stored in tEXt chunk unchanged
+func isPNGASCII(text string) bool {
+	for i := 0; i < len(text); i++ {
+		if text[i] == 0 || text[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
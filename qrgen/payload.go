@@ -0,0 +1,276 @@
+package qrgen
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Escapes special characters of WiFi network fields
+var wifiEscaper = strings.NewReplacer(`\`, `\\`, `;`, `\;`, `,`, `\,`, `:`, `\:`)
+
+// WiFiPayload builds WiFi network join payload, auth is WPA, WEP or nopass
+func WiFiPayload(ssid, password, auth string, hidden bool) (string, error) {
+	if len(ssid) == 0 {
+		return "", fmt.Errorf("SSID is required")
+	}
+
+	var builder strings.Builder
+	switch strings.ToUpper(auth) {
+	case "WPA", "WEP":
+		if len(password) == 0 {
+			return "", fmt.Errorf("password is required for %s network", strings.ToUpper(auth))
+		}
+		fmt.Fprintf(&builder, "WIFI:T:%s;S:%s;P:%s;", strings.ToUpper(auth), wifiEscaper.Replace(ssid), wifiEscaper.Replace(password))
+	case "NOPASS":
+		fmt.Fprintf(&builder, "WIFI:T:nopass;S:%s;", wifiEscaper.Replace(ssid))
+	default:
+		return "", fmt.Errorf("invalid authentication type '%s', choose from WPA, WEP, nopass", auth)
+	}
+	if hidden {
+		builder.WriteString("H:true;")
+	}
+	builder.WriteString(";")
+
+	return builder.String(), nil
+}
+
+// Escapes special characters of vCard text values
+var vcardEscaper = strings.NewReplacer(`\`, `\\`, `;`, `\;`, `,`, `\,`, "\r\n", `\n`, "\n", `\n`)
+
+// VCardPayload builds vCard 3.0 contact payload, only name is required
+func VCardPayload(name, phone, email, org, url string) (string, error) {
+	if len(name) == 0 {
+		return "", fmt.Errorf("contact name is required")
+	}
+
+	// Structured name is family;given, family name is the last word
+	family, given := name, ""
+	if i := strings.LastIndex(name, " "); i >= 0 {
+		family, given = name[i+1:], name[:i]
+	}
+
+	// vCard lines must end with CRLF
+	lines := []string{
+		"BEGIN:VCARD",
+		"VERSION:3.0",
+		fmt.Sprintf("N:%s;%s;;;", vcardEscaper.Replace(family), vcardEscaper.Replace(given)),
+		"FN:" + vcardEscaper.Replace(name),
+	}
+	if len(org) > 0 {
+		lines = append(lines, "ORG:"+vcardEscaper.Replace(org))
+	}
+	if len(phone) > 0 {
+		lines = append(lines, "TEL;TYPE=CELL:"+vcardEscaper.Replace(phone))
+	}
+	if len(email) > 0 {
+		lines = append(lines, "EMAIL;TYPE=INTERNET:"+vcardEscaper.Replace(email))
+	}
+	if len(url) > 0 {
+		lines = append(lines, "URL:"+vcardEscaper.Replace(url))
+	}
+	lines = append(lines, "END:VCARD")
+
+	return strings.Join(lines, "\r\n") + "\r\n", nil
+}
+
+// MailtoPayload builds mailto URI for comma-separated recipients, subject and body are optional
+func MailtoPayload(to, subject, body string) (string, error) {
+	var recipients []string
+	for _, address := range strings.Split(to, ",") {
+		address = strings.TrimSpace(address)
+		if len(address) == 0 {
+			continue
+		}
+		if !strings.Contains(address, "@") {
+			return "", fmt.Errorf("invalid email address '%s'", address)
+		}
+		recipients = append(recipients, url.PathEscape(address))
+	}
+	if len(recipients) == 0 {
+		return "", fmt.Errorf("at least one recipient is required")
+	}
+
+	var query []string
+	if len(subject) > 0 {
+		query = append(query, "subject="+mailtoEscape(subject))
+	}
+	if len(body) > 0 {
+		// Line breaks in body must be encoded as CRLF
+		body = strings.ReplaceAll(strings.ReplaceAll(body, "\r\n", "\n"), "\n", "\r\n")
+		query = append(query, "body="+mailtoEscape(body))
+	}
+
+	payload := "mailto:" + strings.Join(recipients, ",")
+	if len(query) > 0 {
+		payload += "?" + strings.Join(query, "&")
+	}
+	return payload, nil
+}
+
+// mailtoEscape percent-encodes mailto header value, spaces become %20 since mail clients do not
+// decode + as space
+func mailtoEscape(value string) string {
+	return strings.ReplaceAll(url.QueryEscape(value), "+", "%20")
+}
+
+// NormalizePhone strips spaces and dashes from phone number keeping leading +
+func NormalizePhone(number string) (string, error) {
+	normalized := strings.NewReplacer(" ", "", "-", "").Replace(strings.TrimSpace(number))
+	if len(normalized) == 0 {
+		return "", fmt.Errorf("phone number is required")
+	}
+
+	digits := strings.TrimPrefix(normalized, "+")
+	if len(digits) == 0 || strings.Trim(digits, "0123456789") != "" {
+		return "", fmt.Errorf("invalid phone number '%s'", number)
+	}
+	return normalized, nil
+}
+
+// SMSPayload builds SMSTO payload which opens SMS to number with optional prefilled message
+func SMSPayload(number, message string) (string, error) {
+	normalized, err := NormalizePhone(number)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("SMSTO:%s:%s", normalized, message), nil
+}
+
+// TelPayload builds tel URI which dials number
+func TelPayload(number string) (string, error) {
+	normalized, err := NormalizePhone(number)
+	if err != nil {
+		return "", err
+	}
+	return "tel:" + normalized, nil
+}
+
+// GeoPayload builds RFC 5870 geo URI from "lat,lon" or "lat,lon,alt" coordinates, label is added
+// as query understood by map applications. Coordinates copied from map tools may use decimal
+// commas when separated by semicolons or whitespace, e.g. "52,5163; 13,3777".
+func GeoPayload(coords, label string) (string, error) {
+	parts := splitCoordinates(coords)
+	if len(parts) != 2 && len(parts) != 3 {
+		return "", fmt.Errorf("invalid coordinates '%s' (expected lat,lon or lat,lon,alt, separate values with ';' when using decimal commas)", coords)
+	}
+
+	values := make([]float64, len(parts))
+	for i, part := range parts {
+		value, err := parseCoordinate(part, coords)
+		if err != nil {
+			return "", err
+		}
+		values[i] = value
+	}
+	if values[0] < -90 || values[0] > 90 {
+		return "", fmt.Errorf("latitude %g is out of range -90..90", values[0])
+	}
+	if values[1] < -180 || values[1] > 180 {
+		return "", fmt.Errorf("longitude %g is out of range -180..180", values[1])
+	}
+
+	formatted := make([]string, len(values))
+	for i, value := range values {
+		formatted[i] = strconv.FormatFloat(value, 'f', -1, 64)
+	}
+	point := strings.Join(formatted, ",")
+
+	payload := "geo:" + point
+	if len(label) > 0 {
+		payload += fmt.Sprintf("?q=%s,%s(%s)", formatted[0], formatted[1], url.QueryEscape(label))
+	}
+	return payload, nil
+}
+
+// splitCoordinates splits coordinates at semicolons, at whitespace with optional comma, or at commas
+// when there is neither, so commas are decimal separators in the first two cases only
+func splitCoordinates(coords string) []string {
+	coords = strings.TrimSpace(coords)
+	if strings.Contains(coords, ";") {
+		parts := strings.Split(coords, ";")
+		for i, part := range parts {
+			parts[i] = strings.TrimSpace(part)
+		}
+		return parts
+	}
+	if strings.ContainsFunc(coords, unicode.IsSpace) {
+		var parts []string
+		for _, field := range strings.Fields(coords) {
+			if field = strings.TrimSuffix(field, ","); len(field) > 0 {
+				parts = append(parts, field)
+			}
+		}
+		return parts
+	}
+	return strings.Split(coords, ",")
+}
+
+// parseCoordinate parses value of coords with decimal point or comma, value with both or with
+// several commas is rejected as ambiguous
+func parseCoordinate(value, coords string) (float64, error) {
+	normalized := value
+	if strings.Contains(value, ",") {
+		if strings.Contains(value, ".") || strings.Count(value, ",") > 1 {
+			return 0, fmt.Errorf("ambiguous coordinate '%s' in '%s', use either decimal point or decimal comma", value, coords)
+		}
+		normalized = strings.Replace(value, ",", ".", 1)
+	}
+	number, err := strconv.ParseFloat(normalized, 64)
+	if err != nil || math.IsNaN(number) || math.IsInf(number, 0) {
+		return 0, fmt.Errorf("invalid coordinate '%s' in '%s'", value, coords)
+	}
+	return number, nil
+}
+
+// Layouts accepted for event times, times without zone are in local time
+var eventTimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// ParseEventTime parses event time in RFC 3339, "2006-01-02 15:04" or "2006-01-02" layout
+func ParseEventTime(value string) (time.Time, error) {
+	for _, layout := range eventTimeLayouts {
+		if t, err := time.ParseInLocation(layout, strings.TrimSpace(value), time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time '%s' (expected e.g. 2006-01-02 15:04 or RFC 3339)", value)
+}
+
+// EventPayload builds iCalendar VEVENT payload with times in UTC, zero end time is omitted
+func EventPayload(title, location string, start, end time.Time) (string, error) {
+	if len(title) == 0 {
+		return "", fmt.Errorf("event title is required")
+	}
+	if start.IsZero() {
+		return "", fmt.Errorf("event start is required")
+	}
+	if !end.IsZero() && !end.After(start) {
+		return "", fmt.Errorf("event end must be after start")
+	}
+
+	// iCalendar shares escaping and CRLF line endings with vCard
+	const layout = "20060102T150405Z"
+	lines := []string{
+		"BEGIN:VEVENT",
+		"SUMMARY:" + vcardEscaper.Replace(title),
+		"DTSTART:" + start.UTC().Format(layout),
+	}
+	if !end.IsZero() {
+		lines = append(lines, "DTEND:"+end.UTC().Format(layout))
+	}
+	if len(location) > 0 {
+		lines = append(lines, "LOCATION:"+vcardEscaper.Replace(location))
+	}
+	lines = append(lines, "END:VEVENT")
+
+	return strings.Join(lines, "\r\n") + "\r\n", nil
+}
@@ -0,0 +1,66 @@
+package qrgen
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+
+	"github.com/skip2/go-qrcode"
+)
+
+// GeneratePDF generates single page pdf document of size x size points with modules drawn as
+// filled rectangles and quiet zone of border modules
+func GeneratePDF(qr *qrcode.QRCode, size, border int) ([]byte, error) {
+	bitmap := moduleBitmap(qr, border)
+	dim := len(bitmap)
+	unit := float64(size) / float64(dim)
+
+	// Page content, PDF origin is the bottom left corner
+	var content bytes.Buffer
+	fmt.Fprintf(&content, "%s rg\n0 0 %d %d re f\n", pdfColor(qr.BackgroundColor), size, size)
+	fmt.Fprintf(&content, "%s rg\n", pdfColor(qr.ForegroundColor))
+	for y := 0; y < dim; y++ {
+		for x := 0; x < dim; x++ {
+			if !bitmap[y][x] {
+				continue
+			}
+			start := x
+			for x < dim && bitmap[y][x] {
+				x++
+			}
+			fmt.Fprintf(&content, "%.3f %.3f %.3f %.3f re\n", float64(start)*unit, float64(dim-y-1)*unit, float64(x-start)*unit, unit)
+		}
+	}
+	content.WriteString("f\n")
+
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << >> /Contents 4 0 R >>", size, size),
+		fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()),
+	}
+
+	// Write objects and remember their offsets for the cross-reference table
+	var doc bytes.Buffer
+	doc.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = doc.Len()
+		fmt.Fprintf(&doc, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+
+	xref := doc.Len()
+	fmt.Fprintf(&doc, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&doc, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&doc, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	return doc.Bytes(), nil
+}
+
+// pdfColor formats color as pdf rgb operands
+func pdfColor(c color.Color) string {
+	r, g, b, _ := c.RGBA()
+	return fmt.Sprintf("%.3f %.3f %.3f", float64(r)/0xffff, float64(g)/0xffff, float64(b)/0xffff)
+}
@@ -0,0 +1,625 @@
+// Package qrgen generates QR codes in png, svg, jpeg, gif, webp, tiff, pdf and eps formats, and as
+// text and json matrix of modules.
+package qrgen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/skip2/go-qrcode"
+)
+
+// Limits and defaults of generation options
+const (
+	DefaultSize    = 256
+	MinSize        = 100
+	MaxSize        = 4096
+	DefaultUnit    = 6
+	MinUnit        = 1
+	MaxUnit        = 100
+	DefaultQuality = 90
+	MinQuality     = 1
+	MaxQuality     = 100
+	DefaultBorder  = 4
+	DefaultDPI     = 300
+	MinDPI         = 72
+	MaxDPI         = 2400
+	MinScale       = 1
+	MaxScale       = 100
+	MinVersion     = 1
+	MaxVersion     = 40
+	MaxRadius      = 0.5
+	// MaxBinaryBytes is the byte mode capacity of the largest QR code (version 40, level L)
+	MaxBinaryBytes = 2953
+)
+
+// ErrCapacityExceeded is returned when content does not fit into a QR code at the selected level
+var ErrCapacityExceeded = errors.New("content exceeds QR code capacity")
+
+// Options holds settings of a single QR code generation
+type Options struct {
+	// Content is the payload encoded in the QR code
+	Content string
+	// Size is the image width and height in pixels, points for pdf and eps
+	Size int
+	// Scale is the number of pixels per module replacing Size and Unit when set, the image size
+	// follows the module count of the version
+	Scale int
+	// Millimeters is the physical image width, Size should be PixelSize of it at DPI. Then svg
+	// dimensions are in mm, pdf and eps are sized in points and png records DPI.
+	Millimeters float64
+	DPI         int
+	// Level is the error correction level
+	Level qrcode.RecoveryLevel
+	// AutoLevel selects the highest level content fits into, Level is ignored
+	AutoLevel bool
+	// Version forces the QR version and so the module count, the smallest version content fits
+	// into is used when zero
+	Version int
+	// Format is one of the supported output formats
+	Format string
+	// Foreground and Background are hex colors, Background can be "none" for svg and png
+	Foreground string
+	Background string
+	// BorderColor is hex color of the quiet zone of raster and svg output, Background when empty
+	BorderColor string
+	// Negative swaps Foreground and Background, dark modules are drawn in background color
+	Negative bool
+	// Unit is the size of one module in pixels for svg
+	Unit int
+	// Responsive svg output scales to its container instead of having fixed pixel size
+	Responsive bool
+	// Minify writes svg output on a single line
+	Minify bool
+	// Alt is the accessible title of svg output, Content when empty
+	Alt string
+	// Shape is the module shape of svg output, ShapeSquare when empty
+	Shape string
+	// Radius rounds corners of dark modules of raster output by fraction of module size, 0 draws
+	// plain squares and MaxRadius circles
+	Radius float64
+	// EyeStyle is the finder pattern style of svg output, EyeSquare when empty
+	EyeStyle string
+	// Gradient is "from,to" or "from,to@angle" spec of svg module fill, replaces Foreground
+	Gradient string
+	// Caption is text written below the QR code of raster and svg output, extending image height
+	// by caption area; CaptionSize is its font height in pixels
+	Caption     string
+	CaptionSize int
+	// CanvasWidth and CanvasHeight center raster and svg output in larger image filled with
+	// Background, zero keeps the image size
+	CanvasWidth  int
+	CanvasHeight int
+	// Frame draws rounded border around raster output with banner of FrameText at the bottom,
+	// extending the image; empty FrameText draws the border only
+	Frame     bool
+	FrameText string
+	// Border is the quiet zone width in modules
+	Border int
+	// Quality is the jpeg quality
+	Quality int
+	// ASCII writes matrix output with # and space instead of 1 and 0
+	ASCII bool
+	// Metadata adds Content, Created time and software name as text chunks to png output,
+	// zero Created time is omitted
+	Metadata bool
+	Created  time.Time
+	// Lossy requests lossy compression, which the webp encoder does not support yet, output is
+	// always lossless
+	Lossy bool
+	// Compression of tiff output, CompressDeflate when empty
+	Compression string
+	// PNGLevel is the compression level of png output, PNGBest when empty
+	PNGLevel string
+	// Logo is drawn over the center of png, jpeg, gif, webp and tiff output, Level is raised to at least
+	// qrcode.High to keep the code scannable
+	Logo image.Image
+	// BackgroundImage is scaled and cropped to fill png, jpeg, gif, webp and tiff output behind
+	// dark modules, it replaces Background in light modules and the quiet zone
+	BackgroundImage image.Image
+}
+
+// Result holds generated QR code and its encoded file contents in Format
+type Result struct {
+	QRCode *qrcode.QRCode
+	Data   []byte
+	Format string
+}
+
+// Extension returns file extension of the result format without dot
+func (r Result) Extension() string {
+	return Extension(r.Format)
+}
+
+// DefaultOptions returns options for black on white png of DefaultSize with medium correction level
+func DefaultOptions() Options {
+	return Options{
+		Size:        DefaultSize,
+		Level:       qrcode.Medium,
+		Format:      "png",
+		Foreground:  "#000000",
+		Background:  "#ffffff",
+		Unit:        DefaultUnit,
+		Shape:       ShapeSquare,
+		EyeStyle:    EyeSquare,
+		Border:      DefaultBorder,
+		Quality:     DefaultQuality,
+		CaptionSize: DefaultCaptionSize,
+		FrameText:   DefaultFrameText,
+		DPI:         DefaultDPI,
+	}
+}
+
+// ParseSizeList splits comma-separated list of sizes, duplicates are dropped keeping the order.
+// Sizes are checked against the limits by Validate.
+func ParseSizeList(list string) ([]int, error) {
+	var sizes []int
+	for _, item := range strings.Split(list, ",") {
+		size, err := strconv.Atoi(strings.TrimSpace(item))
+		if err != nil {
+			return nil, fmt.Errorf("invalid size '%s'", strings.TrimSpace(item))
+		}
+		if !slices.Contains(sizes, size) {
+			sizes = append(sizes, size)
+		}
+	}
+	return sizes, nil
+}
+
+// ParseCanvas parses canvas size "WxH" in pixels, e.g. "800x600". Limits are checked by Validate.
+func ParseCanvas(spec string) (int, int, error) {
+	w, h, ok := strings.Cut(strings.ToLower(strings.TrimSpace(spec)), "x")
+	width, errWidth := strconv.Atoi(w)
+	height, errHeight := strconv.Atoi(h)
+	if !ok || errWidth != nil || errHeight != nil {
+		return 0, 0, fmt.Errorf("invalid canvas '%s' (expected WxH, e.g. 800x600)", spec)
+	}
+	return width, height, nil
+}
+
+// Millimeters per inch
+const mmPerInch = 25.4
+
+// PixelSize returns number of pixels covering mm millimeters at dpi pixels per inch
+func PixelSize(mm float64, dpi int) int {
+	return int(math.Round(mm / mmPerInch * float64(dpi)))
+}
+
+// Validate checks options except Content, which is validated by the encoder
+func (opts Options) Validate() error {
+	if opts.Version != 0 && (opts.Version < MinVersion || opts.Version > MaxVersion) {
+		return fmt.Errorf("QR version must be between %d and %d", MinVersion, MaxVersion)
+	}
+	if opts.Scale != 0 && (opts.Scale < MinScale || opts.Scale > MaxScale) {
+		return fmt.Errorf("scale must be between %d and %d pixels per module", MinScale, MaxScale)
+	}
+	if opts.Scale > 0 && opts.Millimeters > 0 {
+		return fmt.Errorf("scale cannot be combined with physical size")
+	}
+	if opts.Millimeters < 0 {
+		return fmt.Errorf("physical size must not be negative")
+	}
+	if opts.Millimeters > 0 && (opts.DPI < MinDPI || opts.DPI > MaxDPI) {
+		return fmt.Errorf("dpi must be between %d and %d", MinDPI, MaxDPI)
+	}
+	if opts.Millimeters > 0 && (opts.Size < MinSize || opts.Size > MaxSize) {
+		return fmt.Errorf("size of %g mm at %d dpi is %d pixels, it must be between %d and %d", opts.Millimeters, opts.DPI, opts.Size, MinSize, MaxSize)
+	}
+	if opts.Size < MinSize || opts.Size > MaxSize {
+		return fmt.Errorf("size of the QR code must be between %d and %d", MinSize, MaxSize)
+	}
+	if opts.CanvasWidth != 0 || opts.CanvasHeight != 0 {
+		if opts.CanvasWidth < 1 || opts.CanvasWidth > MaxSize || opts.CanvasHeight < 1 || opts.CanvasHeight > MaxSize {
+			return fmt.Errorf("canvas width and height must be between 1 and %d", MaxSize)
+		}
+		if !isRasterFormat(opts.Format) && opts.Format != "svg" {
+			return fmt.Errorf("canvas is only supported for png, jpeg, gif, webp, tiff and svg formats")
+		}
+	}
+	if opts.Millimeters > 0 && opts.Responsive {
+		return fmt.Errorf("physical size cannot be combined with responsive output")
+	}
+	if opts.Unit < MinUnit || opts.Unit > MaxUnit {
+		return fmt.Errorf("module size must be between %d and %d", MinUnit, MaxUnit)
+	}
+	if opts.Border < 0 {
+		return fmt.Errorf("border width must not be negative")
+	}
+	if opts.Quality < MinQuality || opts.Quality > MaxQuality {
+		return fmt.Errorf("JPEG quality must be between %d and %d", MinQuality, MaxQuality)
+	}
+	if opts.Level < qrcode.Low || opts.Level > qrcode.Highest {
+		return fmt.Errorf("invalid correction level %d", opts.Level)
+	}
+	if !IsValidFormat(opts.Format) {
+		return fmt.Errorf("unsupported file format '%s', supported formats are %s", opts.Format, FormatList())
+	}
+	switch opts.Shape {
+	case "", ShapeSquare:
+	case ShapeCircle:
+		if opts.Format != "svg" {
+			return fmt.Errorf("shape '%s' is only supported for svg format", opts.Shape)
+		}
+	default:
+		return fmt.Errorf("invalid shape '%s', choose from %s, %s", opts.Shape, ShapeSquare, ShapeCircle)
+	}
+	switch opts.EyeStyle {
+	case "", EyeSquare:
+	case EyeRounded:
+		if opts.Format != "svg" {
+			return fmt.Errorf("eye style '%s' is only supported for svg format", opts.EyeStyle)
+		}
+	default:
+		return fmt.Errorf("invalid eye style '%s', choose from %s, %s", opts.EyeStyle, EyeSquare, EyeRounded)
+	}
+	if opts.Radius < 0 || opts.Radius > MaxRadius {
+		return fmt.Errorf("module radius must be between 0 and %g", MaxRadius)
+	}
+	if opts.Radius > 0 && !isRasterFormat(opts.Format) {
+		return fmt.Errorf("module radius is only supported for png, jpeg, gif, webp and tiff formats, use shape '%s' for svg", ShapeCircle)
+	}
+	if opts.Responsive && opts.Format != "svg" {
+		return fmt.Errorf("responsive output is only supported for svg format")
+	}
+	if opts.ASCII && opts.Format != "matrix" {
+		return fmt.Errorf("ascii output is only supported for matrix format")
+	}
+	if opts.Minify && opts.Format != "svg" {
+		return fmt.Errorf("minified output is only supported for svg format")
+	}
+	if opts.Negative && (opts.Background == TransparentColor || len(opts.Gradient) > 0) {
+		return fmt.Errorf("negative cannot be combined with transparent background or gradient")
+	}
+	if len(opts.Gradient) > 0 {
+		if opts.Format != "svg" {
+			return fmt.Errorf("gradient is only supported for svg format")
+		}
+		if _, err := ParseGradient(opts.Gradient); err != nil {
+			return fmt.Errorf("gradient: %w", err)
+		}
+	}
+	if len(opts.Caption) > 0 {
+		if !isRasterFormat(opts.Format) && opts.Format != "svg" {
+			return fmt.Errorf("caption is only supported for png, jpeg, gif, webp, tiff and svg formats")
+		}
+		if opts.CaptionSize < MinCaptionSize || opts.CaptionSize > MaxCaptionSize {
+			return fmt.Errorf("caption size must be between %d and %d", MinCaptionSize, MaxCaptionSize)
+		}
+	}
+	if opts.Frame && !isRasterFormat(opts.Format) {
+		return fmt.Errorf("frame is only supported for png, jpeg, gif, webp and tiff formats")
+	}
+	switch opts.Compression {
+	case "", CompressDeflate, CompressNone:
+	case CompressLZW:
+		return fmt.Errorf("%s compression is not supported by the tiff encoder, choose from %s, %s", CompressLZW, CompressDeflate, CompressNone)
+	default:
+		return fmt.Errorf("invalid compression '%s', choose from %s, %s", opts.Compression, CompressDeflate, CompressNone)
+	}
+	if _, ok := pngLevels[opts.PNGLevel]; !ok && len(opts.PNGLevel) > 0 {
+		return fmt.Errorf("invalid png compression level '%s', choose from %s, %s, %s", opts.PNGLevel, PNGSpeed, PNGDefault, PNGBest)
+	}
+	if opts.Lossy {
+		return fmt.Errorf("lossy webp not supported, the webp encoder writes lossless output only")
+	}
+	if opts.Metadata && opts.Format != "png" {
+		return fmt.Errorf("metadata is only supported for png format")
+	}
+	if opts.Logo != nil && !isRasterFormat(opts.Format) {
+		return fmt.Errorf("logo is only supported for png, jpeg, gif, webp and tiff formats")
+	}
+	if opts.BackgroundImage != nil {
+		if !isRasterFormat(opts.Format) {
+			return fmt.Errorf("background image is only supported for png, jpeg, gif, webp and tiff formats")
+		}
+		if opts.Background == TransparentColor || opts.Negative {
+			return fmt.Errorf("background image cannot be combined with transparent background or negative")
+		}
+	}
+
+	if _, err := ParseHexColor(opts.Foreground); err != nil {
+		return fmt.Errorf("foreground: %w", err)
+	}
+	if len(opts.BorderColor) > 0 {
+		if !isRasterFormat(opts.Format) && opts.Format != "svg" {
+			return fmt.Errorf("border color is only supported for png, jpeg, gif, webp, tiff and svg formats")
+		}
+		if _, err := ParseHexColor(opts.BorderColor); err != nil {
+			return fmt.Errorf("border color: %w", err)
+		}
+	}
+	// Transparent background is available for svg and png output only
+	if opts.Background == TransparentColor {
+		if opts.Format != "svg" && opts.Format != "png" {
+			return fmt.Errorf("background '%s' is only supported for svg and png formats", TransparentColor)
+		}
+	} else if _, err := ParseHexColor(opts.Background); err != nil {
+		return fmt.Errorf("background: %w", err)
+	}
+
+	return nil
+}
+
+// LevelAuto is the level name which selects automatic correction level
+const LevelAuto = "auto"
+
+// LevelNames returns standard names of correction levels from lowest to highest
+func LevelNames() []string {
+	return []string{"L", "M", "Q", "H"}
+}
+
+// ParseLevel converts standard correction level name (L, M, Q, H) to recovery level
+func ParseLevel(name string) (qrcode.RecoveryLevel, error) {
+	switch name {
+	case "L":
+		return qrcode.Low, nil
+	case "M":
+		return qrcode.Medium, nil
+	case "Q":
+		return qrcode.High, nil
+	case "H":
+		return qrcode.Highest, nil
+	default:
+		return 0, fmt.Errorf("invalid correction level '%s', choose from L, M, Q, H", name)
+	}
+}
+
+// LevelName returns standard name (L, M, Q, H) of recovery level
+func LevelName(level qrcode.RecoveryLevel) string {
+	switch level {
+	case qrcode.Low:
+		return "L"
+	case qrcode.Medium:
+		return "M"
+	case qrcode.High:
+		return "Q"
+	case qrcode.Highest:
+		return "H"
+	default:
+		return fmt.Sprintf("%d", level)
+	}
+}
+
+// SymbolSize returns width of QR code in modules without quiet zone
+func SymbolSize(qr *qrcode.QRCode) int {
+	return 17 + 4*qr.VersionNumber
+}
+
+// Generate validates options, encodes content and renders it in selected format
+func Generate(opts Options) (Result, error) {
+	if err := opts.Validate(); err != nil {
+		return Result{}, err
+	}
+
+	qr, err := newQRCode(opts)
+	if err != nil {
+		return Result{}, err
+	}
+
+	data, err := encode(qr, opts)
+	if err != nil {
+		return Result{}, err
+	}
+
+	return Result{QRCode: qr, Data: data, Format: opts.Format}, nil
+}
+
+// GenerateFormats encodes content once and renders the QR code in every format, results are in the
+// order of formats and opts.Format is ignored
+func GenerateFormats(opts Options, formats []string) ([]Result, error) {
+	return GenerateSizes(opts, formats, []int{opts.Size})
+}
+
+// GenerateSizes encodes content once and renders the QR code in every format at every size, results
+// are ordered by size and then by format, opts.Size and opts.Format are ignored
+func GenerateSizes(opts Options, formats []string, sizes []int) ([]Result, error) {
+	for _, size := range sizes {
+		for _, format := range formats {
+			opts.Size, opts.Format = size, format
+			if err := opts.Validate(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	qr, err := newQRCode(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]Result, 0, len(sizes)*len(formats))
+	for _, size := range sizes {
+		for _, format := range formats {
+			opts.Size, opts.Format = size, format
+			data, err := encode(qr, opts)
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, Result{QRCode: qr, Data: data, Format: format})
+		}
+	}
+
+	return results, nil
+}
+
+// GenerateContext is Generate which gives up when ctx is done
+func GenerateContext(ctx context.Context, opts Options) (Result, error) {
+	results, err := GenerateFormatsContext(ctx, opts, []string{opts.Format})
+	if err != nil {
+		return Result{}, err
+	}
+	return results[0], nil
+}
+
+// GenerateFormatsContext is GenerateFormats which gives up when ctx is done
+func GenerateFormatsContext(ctx context.Context, opts Options, formats []string) ([]Result, error) {
+	return GenerateSizesContext(ctx, opts, formats, []int{opts.Size})
+}
+
+// GenerateSizesContext is GenerateSizes which gives up when ctx is done. Encoder can not be
+// interrupted, so abandoned generation keeps running in background until it finishes.
+func GenerateSizesContext(ctx context.Context, opts Options, formats []string, sizes []int) ([]Result, error) {
+	type outcome struct {
+		results []Result
+		err     error
+	}
+
+	// Buffered so abandoned goroutine does not block forever
+	done := make(chan outcome, 1)
+	go func() {
+		results, err := GenerateSizes(opts, formats, sizes)
+		done <- outcome{results: results, err: err}
+	}()
+
+	select {
+	case o := <-done:
+		return o.results, o.err
+	case <-ctx.Done():
+		return nil, fmt.Errorf("generation stopped: %w", ctx.Err())
+	}
+}
+
+// newQRCode encodes content and applies colors from options
+func newQRCode(opts Options) (*qrcode.QRCode, error) {
+	minLevel := qrcode.Low
+	if opts.Logo != nil {
+		minLevel = qrcode.High
+	}
+
+	level := max(opts.Level, minLevel)
+	if opts.AutoLevel {
+		level = qrcode.Highest
+	}
+
+	// Encoder only fails on content which does not fit into the largest or forced version,
+	// auto level steps down until it fits
+	qr, err := encodeContent(opts.Content, opts.Version, level)
+	for err != nil && opts.AutoLevel && level > minLevel {
+		level--
+		qr, err = encodeContent(opts.Content, opts.Version, level)
+	}
+	if err != nil {
+		return nil, capacityError(opts, level, minLevel)
+	}
+
+	// Light modules of raster output become fully transparent
+	qr.ForegroundColor, _ = ParseHexColor(opts.Foreground)
+	if opts.Background != TransparentColor {
+		qr.BackgroundColor, _ = ParseHexColor(opts.Background)
+	} else {
+		qr.BackgroundColor = color.Transparent
+	}
+	if opts.Negative {
+		qr.ForegroundColor, qr.BackgroundColor = qr.BackgroundColor, qr.ForegroundColor
+	}
+
+	return qr, nil
+}
+
+// capacityError describes content which does not fit at level with payload length and capacity, and
+// suggests lower level unless minLevel is already reached
+func capacityError(opts Options, level, minLevel qrcode.RecoveryLevel) error {
+	where := fmt.Sprintf("at level %s", LevelName(level))
+	if opts.Version > 0 {
+		where = fmt.Sprintf("of version %d %s", opts.Version, where)
+	}
+	hint := "shorten the content"
+	if level > minLevel {
+		hint = fmt.Sprintf("use a lower correction level, e.g. %s, or shorten the content", LevelName(minLevel))
+	}
+	return fmt.Errorf("%w %s: payload is %d bytes, about %d bytes fit; %s",
+		ErrCapacityExceeded, where, len(opts.Content), byteCapacity(opts.Version, level), hint)
+}
+
+// byteCapacity finds the longest text of byte mode characters which fits into version at level, or
+// into the largest version when version is zero. Digits and upper case text fit more.
+func byteCapacity(version int, level qrcode.RecoveryLevel) int {
+	return modeCapacity(ModeByte, version, level)
+}
+
+// encodeContent encodes content in the smallest version it fits into, or in version when set
+func encodeContent(content string, version int, level qrcode.RecoveryLevel) (*qrcode.QRCode, error) {
+	if version > 0 {
+		return qrcode.NewWithForcedVersion(content, version, level)
+	}
+	return qrcode.New(content, level)
+}
+
+// ScaledSize returns image size of QR code with quiet zone of border modules at scale pixels per module
+func ScaledSize(qr *qrcode.QRCode, scale, border int) int {
+	return scale * (SymbolSize(qr) + 2*border)
+}
+
+// encode renders QR code to file contents with encoder of selected format
+func encode(qr *qrcode.QRCode, opts Options) ([]byte, error) {
+	if opts.Scale > 0 {
+		size := ScaledSize(qr, opts.Scale, opts.Border)
+		if size > MaxSize {
+			return nil, fmt.Errorf("scale %d gives %d pixels for QR version %d, more than %d", opts.Scale, size, qr.VersionNumber, MaxSize)
+		}
+		opts.Size, opts.Unit = size, opts.Scale
+	}
+
+	encoder, ok := encoders[opts.Format]
+	if !ok {
+		return nil, fmt.Errorf("invalid format '%s'", opts.Format)
+	}
+	return encoder.Encode(qr, opts)
+}
+
+// encodeSVG renders QR code as svg with style from options
+func encodeSVG(qr *qrcode.QRCode, opts Options) ([]byte, error) {
+	style := SVGStyle{
+		Foreground:   HexColor(qr.ForegroundColor),
+		Background:   TransparentColor,
+		Unit:         opts.Unit,
+		Border:       opts.Border,
+		Shape:        opts.Shape,
+		EyeStyle:     opts.EyeStyle,
+		Caption:      opts.Caption,
+		CaptionSize:  opts.CaptionSize,
+		Responsive:   opts.Responsive,
+		Minify:       opts.Minify,
+		Title:        opts.Alt,
+		Millimeters:  opts.Millimeters,
+		CanvasWidth:  opts.CanvasWidth,
+		CanvasHeight: opts.CanvasHeight,
+	}
+	if len(opts.BorderColor) > 0 {
+		borderColor, _ := ParseHexColor(opts.BorderColor)
+		style.BorderColor = HexColor(borderColor)
+	}
+	if len(style.Title) == 0 {
+		style.Title = opts.Content
+	}
+	if opts.Background != TransparentColor {
+		style.Background = HexColor(qr.BackgroundColor)
+	}
+	if len(opts.Gradient) > 0 {
+		gradient, _ := ParseGradient(opts.Gradient)
+		style.Gradient = &gradient
+	}
+	if width, height := svgSize(qr, style); style.CanvasWidth > 0 && (width > style.CanvasWidth || height > style.CanvasHeight) {
+		return nil, canvasError(width, height, style.CanvasWidth, style.CanvasHeight)
+	}
+	return encodeBuffer(func(w io.Writer) error {
+		return WriteSVG(w, qr, style)
+	})
+}
+
+// pointSize returns size of pdf and eps output in points, 1/72 of an inch
+func pointSize(opts Options) int {
+	if opts.Millimeters > 0 {
+		return PixelSize(opts.Millimeters, 72)
+	}
+	return opts.Size
+}
@@ -0,0 +1,218 @@
+package qrgen
+
+import (
+	"encoding/xml"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/skip2/go-qrcode"
+)
+
+// Module shapes of svg output
+const (
+	ShapeSquare = "square"
+	ShapeCircle = "circle"
+)
+
+// Finder pattern styles of svg output
+const (
+	EyeSquare  = "square"
+	EyeRounded = "rounded"
+)
+
+// Radius of circle modules relative to module size, leaves a small gap between neighbours
+const circleRadius = 0.45
+
+// Id of the gradient referenced by module fill
+const gradientID = "grad"
+
+// SVGStyle holds rendering settings of svg output
+type SVGStyle struct {
+	// Foreground and Background are svg fill values, Background can be "none"
+	Foreground string
+	Background string
+	// Unit is the size of one module in pixels
+	Unit int
+	// Border is the quiet zone width in modules
+	Border int
+	// Shape of data modules and EyeStyle of finder patterns
+	Shape    string
+	EyeStyle string
+	// Gradient replaces Foreground when set
+	Gradient *Gradient
+	// Caption is written below the QR code with font height of CaptionSize pixels when set
+	Caption     string
+	CaptionSize int
+	// Responsive replaces fixed pixel size with viewBox scaling to the container
+	Responsive bool
+	// Minify writes all elements on a single line
+	Minify bool
+	// Title is the accessible name read by screen readers, omitted when empty
+	Title string
+	// Millimeters is the physical width, replaces pixel size of the root element when set
+	Millimeters float64
+	// BorderColor is svg fill of the quiet zone, which is left in Background when empty
+	BorderColor string
+	// CanvasWidth and CanvasHeight enlarge the image, which is centered in it, when set; the QR code
+	// must fit into the canvas
+	CanvasWidth  int
+	CanvasHeight int
+}
+
+// svgSize returns width and height of svg image in pixels without canvas, caption extends the height
+func svgSize(qr *qrcode.QRCode, style SVGStyle) (int, int) {
+	width := (SymbolSize(qr) + 2*style.Border) * style.Unit
+	if len(style.Caption) > 0 {
+		return width, width + captionHeight(style.CaptionSize)
+	}
+	return width, width
+}
+
+// Description of svg output read by screen readers
+const svgDescription = "QR code"
+
+// GenerateSVG generates svg vector image as string. With ShapeCircle data modules are drawn as dots,
+// finder patterns are drawn in style.EyeStyle.
+func GenerateSVG(qr *qrcode.QRCode, style SVGStyle) string {
+	var builder strings.Builder
+
+	unit, border, shape, eyeStyle := style.Unit, style.Border, style.Shape, style.EyeStyle
+	bitmap := moduleBitmap(qr, border)
+	dim := len(bitmap)
+
+	// Caption extends the image below the quiet zone, canvas centers the image in larger one
+	width, height := svgSize(qr, style)
+	outWidth, outHeight := width, height
+	if style.CanvasWidth > 0 {
+		outWidth, outHeight = style.CanvasWidth, style.CanvasHeight
+	}
+
+	// Use fmt.Fprintf for direct writing to builder
+	size := fmt.Sprintf("width=\"%d\" height=\"%d\"", outWidth, outHeight)
+	if style.Responsive {
+		size = fmt.Sprintf("viewBox=\"0 0 %d %d\" width=\"100%%\" height=\"100%%\"", outWidth, outHeight)
+	} else if style.Millimeters > 0 {
+		// Caption and canvas keep their proportion of the height
+		mmHeight := style.Millimeters * float64(outHeight) / float64(outWidth)
+		size = fmt.Sprintf("viewBox=\"0 0 %d %d\" width=\"%gmm\" height=\"%gmm\"", outWidth, outHeight, style.Millimeters, math.Round(mmHeight*1000)/1000)
+	}
+	if len(style.Title) > 0 {
+		title := xmlEscape(style.Title)
+		fmt.Fprintf(&builder, "<svg %s xmlns=\"http://www.w3.org/2000/svg\" role=\"img\" aria-label=\"%s\">\n", size, title)
+		fmt.Fprintf(&builder, "<title>%s</title>\n", title)
+	} else {
+		fmt.Fprintf(&builder, "<svg %s xmlns=\"http://www.w3.org/2000/svg\" role=\"img\">\n", size)
+	}
+	fmt.Fprintf(&builder, "<desc>%s</desc>\n", svgDescription)
+
+	// Gradient spans the whole image, so every module shows its part of it
+	fg := style.Foreground
+	if style.Gradient != nil {
+		center := float64(dim*unit) / 2
+		builder.WriteString("<defs>\n")
+		fmt.Fprintf(&builder, "<linearGradient id=\"%s\" gradientUnits=\"userSpaceOnUse\" x1=\"0\" y1=\"0\" x2=\"%d\" y2=\"0\" gradientTransform=\"rotate(%g %g %g)\">\n",
+			gradientID, dim*unit, style.Gradient.Angle, center, center)
+		fmt.Fprintf(&builder, "<stop offset=\"0\" stop-color=\"%s\"/>\n", HexColor(style.Gradient.From))
+		fmt.Fprintf(&builder, "<stop offset=\"1\" stop-color=\"%s\"/>\n", HexColor(style.Gradient.To))
+		builder.WriteString("</linearGradient>\n</defs>\n")
+		fg = fmt.Sprintf("url(#%s)", gradientID)
+	}
+
+	if style.Background != TransparentColor {
+		fmt.Fprintf(&builder, "<rect width=\"%d\" height=\"%d\" fill=\"%s\"/>\n", outWidth, outHeight, style.Background)
+	}
+	// Gradient in user space moves along with the modules
+	shifted := outWidth != width || outHeight != height
+	if shifted {
+		fmt.Fprintf(&builder, "<g transform=\"translate(%d %d)\">\n", (outWidth-width)/2, (outHeight-height)/2)
+	}
+
+	// Quiet zone is the image square with the symbol cut out, so transparent background stays
+	if len(style.BorderColor) > 0 && border > 0 {
+		inner := (dim - 2*border) * unit
+		fmt.Fprintf(&builder, "<path fill=\"%s\" fill-rule=\"evenodd\" d=\"M0 0h%dv%dh-%dzM%d %dh%dv%dh-%dz\"/>\n",
+			style.BorderColor, dim*unit, dim*unit, dim*unit, border*unit, border*unit, inner, inner, inner)
+	}
+
+	// Modules drawn as squares, all of them by default
+	square := func(x, y int) bool {
+		if isFinderModule(x, y, border, dim) {
+			return bitmap[y][x] && eyeStyle != EyeRounded
+		}
+		return bitmap[y][x] && shape != ShapeCircle
+	}
+
+	// Merge horizontal runs of dark modules into one path instead of a rect per module
+	fmt.Fprintf(&builder, "<path fill=\"%s\" d=\"", fg)
+	for y := 0; y < dim; y++ {
+		for x := 0; x < dim; x++ {
+			if !square(x, y) {
+				continue
+			}
+			start := x
+			for x < dim && square(x, y) {
+				x++
+			}
+			width := (x - start) * unit
+			fmt.Fprintf(&builder, "M%d %dh%dv%dh-%dz", start*unit, y*unit, width, unit, width)
+		}
+	}
+	builder.WriteString("\"/>\n")
+
+	if shape == ShapeCircle {
+		radius := circleRadius * float64(unit)
+		fmt.Fprintf(&builder, "<g fill=\"%s\">\n", fg)
+		for y := 0; y < dim; y++ {
+			for x := 0; x < dim; x++ {
+				if bitmap[y][x] && !isFinderModule(x, y, border, dim) {
+					center := float64(unit) / 2
+					fmt.Fprintf(&builder, "<circle cx=\"%g\" cy=\"%g\" r=\"%g\"/>\n", float64(x*unit)+center, float64(y*unit)+center, radius)
+				}
+			}
+		}
+		builder.WriteString("</g>\n")
+	}
+	if eyeStyle == EyeRounded {
+		writeRoundedEyes(&builder, fg, unit, border, dim)
+	}
+	if len(style.Caption) > 0 {
+		writeSVGCaption(&builder, style.Caption, style.CaptionSize, style.Foreground, dim*unit)
+	}
+	if shifted {
+		builder.WriteString("</g>\n")
+	}
+	builder.WriteString("</svg>")
+
+	// Newlines only separate elements, text content has them escaped
+	if style.Minify {
+		return strings.ReplaceAll(builder.String(), "\n", "")
+	}
+	return builder.String()
+}
+
+// writeRoundedEyes draws finder patterns as rounded ring around rounded center square, the ring is
+// a stroke so the background shows through with transparent background too
+func writeRoundedEyes(builder *strings.Builder, fg string, unit, border, dim int) {
+	far := (dim - border - finderSize) * unit
+	near := border * unit
+	ring := float64((finderSize - 1) * unit)
+	half := float64(unit) / 2
+
+	fmt.Fprintf(builder, "<g fill=\"%s\">\n", fg)
+	for _, origin := range [][2]int{{near, near}, {far, near}, {near, far}} {
+		x, y := float64(origin[0]), float64(origin[1])
+		fmt.Fprintf(builder, "<rect x=\"%g\" y=\"%g\" width=\"%g\" height=\"%g\" rx=\"%g\" fill=\"none\" stroke=\"%s\" stroke-width=\"%d\"/>\n",
+			x+half, y+half, ring, ring, 1.5*float64(unit), fg, unit)
+		fmt.Fprintf(builder, "<rect x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\" rx=\"%g\"/>\n",
+			int(x)+2*unit, int(y)+2*unit, 3*unit, 3*unit, 0.75*float64(unit))
+	}
+	builder.WriteString("</g>\n")
+}
+
+// xmlEscape escapes text for use in svg element content and attribute values
+func xmlEscape(text string) string {
+	var builder strings.Builder
+	_ = xml.EscapeText(&builder, []byte(text))
+	return builder.String()
+}
@@ -0,0 +1,182 @@
+package qrgen
+
+import (
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/skip2/go-qrcode"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files in testdata")
+
+// svgDocument is the part of svg output checked by tests
+type svgDocument struct {
+	XMLName xml.Name `xml:"svg"`
+	Width   string   `xml:"width,attr"`
+	Height  string   `xml:"height,attr"`
+	Paths   []struct {
+		Fill     string `xml:"fill,attr"`
+		FillRule string `xml:"fill-rule,attr"`
+		D        string `xml:"d,attr"`
+	} `xml:"path"`
+}
+
+// Horizontal run of modules in merged module path
+var runPattern = regexp.MustCompile(`M(\d+) (\d+)h(\d+)v(\d+)h-(\d+)z`)
+
+// darkModules counts dark modules of QR code with quiet zone of border modules
+func darkModules(qr *qrcode.QRCode, border int) int {
+	count := 0
+	for _, row := range moduleBitmap(qr, border) {
+		for _, dark := range row {
+			if dark {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// pathModules counts modules covered by runs of svg path data drawn with unit pixels per module
+func pathModules(t *testing.T, d string, unit int) int {
+	t.Helper()
+	if rest := runPattern.ReplaceAllString(d, ""); len(rest) > 0 {
+		t.Fatalf("unexpected path data %q", rest)
+	}
+	count := 0
+	for _, run := range runPattern.FindAllStringSubmatch(d, -1) {
+		width, _ := strconv.Atoi(run[3])
+		height, _ := strconv.Atoi(run[4])
+		if height != unit || width%unit != 0 || run[5] != run[3] {
+			t.Fatalf("run %q is not a row of whole modules of %d pixels", run[0], unit)
+		}
+		count += width / unit
+	}
+	return count
+}
+
+func TestGenerateSVG(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		level   qrcode.RecoveryLevel
+		unit    int
+		border  int
+		caption string
+	}{
+		{"url", "https://www.example.com", qrcode.Medium, DefaultUnit, DefaultBorder, ""},
+		{"no border", "https://www.example.com", qrcode.Low, 4, 0, ""},
+		{"highest level", "https://www.example.com/a/longer/path?with=query", qrcode.Highest, 1, 2, ""},
+		{"numeric", "0123456789", qrcode.Medium, 10, DefaultBorder, ""},
+		{"caption", "https://www.example.com", qrcode.Medium, DefaultUnit, DefaultBorder, "example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qr, err := qrcode.New(tt.content, tt.level)
+			if err != nil {
+				t.Fatal(err)
+			}
+			style := SVGStyle{
+				Foreground:  "#000000",
+				Background:  "#ffffff",
+				Unit:        tt.unit,
+				Border:      tt.border,
+				Caption:     tt.caption,
+				CaptionSize: DefaultCaptionSize,
+			}
+			output := GenerateSVG(qr, style)
+
+			var doc svgDocument
+			if err := xml.Unmarshal([]byte(output), &doc); err != nil {
+				t.Fatalf("output is not well-formed XML: %v", err)
+			}
+
+			dim := (SymbolSize(qr) + 2*tt.border) * tt.unit
+			height := dim
+			if len(tt.caption) > 0 {
+				height += captionHeight(DefaultCaptionSize)
+			}
+			if doc.Width != strconv.Itoa(dim) || doc.Height != strconv.Itoa(height) {
+				t.Errorf("root size is %sx%s, want %dx%d", doc.Width, doc.Height, dim, height)
+			}
+
+			var modules []string
+			for _, path := range doc.Paths {
+				if path.Fill == style.Foreground && len(path.FillRule) == 0 {
+					modules = append(modules, path.D)
+				}
+			}
+			if len(modules) != 1 {
+				t.Fatalf("found %d module paths, want 1", len(modules))
+			}
+			if got, want := pathModules(t, modules[0], tt.unit), darkModules(qr, tt.border); got != want {
+				t.Errorf("path covers %d modules, QR code has %d dark modules", got, want)
+			}
+		})
+	}
+}
+
+func TestGenerateSVGGolden(t *testing.T) {
+	qr, err := qrcode.New("https://www.example.com", qrcode.Medium)
+	if err != nil {
+		t.Fatal(err)
+	}
+	output := GenerateSVG(qr, SVGStyle{
+		Foreground: "#000000",
+		Background: "#ffffff",
+		Unit:       DefaultUnit,
+		Border:     DefaultBorder,
+		Title:      "https://www.example.com",
+	})
+
+	golden := filepath.Join("testdata", "example.svg")
+	if *update {
+		if err := os.WriteFile(golden, []byte(output), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("%v, run go test -update to create it", err)
+	}
+	if output != string(want) {
+		t.Errorf("output differs from %s, run go test -update if the change is intended:\n%s", golden, output)
+	}
+}
+
+// rectSize returns length of markup drawing every dark module as its own rect, the output before
+// runs were merged into one path
+func rectSize(qr *qrcode.QRCode, unit, border int) int {
+	var builder strings.Builder
+	for y, row := range moduleBitmap(qr, border) {
+		for x, dark := range row {
+			if dark {
+				fmt.Fprintf(&builder, "<rect x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\"/>\n", x*unit, y*unit, unit, unit)
+			}
+		}
+	}
+	return builder.Len()
+}
+
+func BenchmarkGenerateSVG(b *testing.B) {
+	qr, err := qrcode.New("https://www.example.com/a/longer/path?with=query&and=more", qrcode.Highest)
+	if err != nil {
+		b.Fatal(err)
+	}
+	style := SVGStyle{Foreground: "#000000", Background: "#ffffff", Unit: DefaultUnit, Border: DefaultBorder}
+
+	var output string
+	for i := 0; i < b.N; i++ {
+		output = GenerateSVG(qr, style)
+	}
+	b.ReportMetric(float64(len(output)), "path-bytes")
+	b.ReportMetric(float64(rectSize(qr, style.Unit, style.Border)), "rect-bytes")
+}
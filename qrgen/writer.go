@@ -0,0 +1,56 @@
+package qrgen
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"io"
+
+	"github.com/skip2/go-qrcode"
+)
+
+// WritePNG streams already encoded QR code as png image rendered with opts in the colors of qr, a
+// convenience wrapper of the png encoder used by Generate, so output is the same as its result with
+// Format png. It takes Options instead of only a size, which could not carry border, radius, logo
+// and the other raster settings. The command line tool does not call it, it streams results of
+// Generate with Result.WriteTo, which hold the same bytes.
+func WritePNG(w io.Writer, qr *qrcode.QRCode, opts Options) error {
+	opts.Format = "png"
+	data, err := encode(qr, opts)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// WriteSVG streams QR code as svg document with style
+func WriteSVG(w io.Writer, qr *qrcode.QRCode, style SVGStyle) error {
+	_, err := io.WriteString(w, GenerateSVG(qr, style))
+	return err
+}
+
+// WriteTo writes encoded file contents to w, so results can be streamed to files, stdout and
+// HTTP responses alike
+func (r Result) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write(r.Data)
+	return int64(n), err
+}
+
+// Results are accepted by io.Copy and similar helpers
+var _ io.WriterTo = Result{}
+
+// writePNG encodes image as png with compression level
+func writePNG(w io.Writer, img image.Image, level png.CompressionLevel) error {
+	encoder := png.Encoder{CompressionLevel: level}
+	return encoder.Encode(w, img)
+}
+
+// encodeBuffer collects streamed output of write
+func encodeBuffer(write func(w io.Writer) error) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := write(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
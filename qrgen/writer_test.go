@@ -0,0 +1,39 @@
+package qrgen
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWritePNGMatchesGenerate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(opts *Options)
+	}{
+		{"defaults", func(opts *Options) {}},
+		{"colors and border", func(opts *Options) { opts.Foreground, opts.Background, opts.Border = "#1a1a1a", "#eeeeee", 1 }},
+		{"scale", func(opts *Options) { opts.Scale = 3 }},
+		{"rounded", func(opts *Options) { opts.Radius = MaxRadius }},
+		{"negative", func(opts *Options) { opts.Negative = true }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.Content, opts.Format = "https://www.example.com", "png"
+			tt.modify(&opts)
+			result, err := Generate(opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var buf bytes.Buffer
+			if err := WritePNG(&buf, result.QRCode, opts); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(buf.Bytes(), result.Data) {
+				t.Errorf("WritePNG wrote %d bytes differing from %d bytes of Generate", buf.Len(), len(result.Data))
+			}
+		})
+	}
+}
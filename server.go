@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mtzvd/qr-generator/qrgen"
+)
+
+// serveQR starts HTTP server answering GET /qr requests, options from command line are the defaults
+// which size, level and format query parameters override
+func serveQR(addr string, defaults qrgen.Options, strict bool, timeout time.Duration, stderr io.Writer) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/qr", func(w http.ResponseWriter, r *http.Request) {
+		handleQR(w, r, defaults, strict, timeout)
+	})
+
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	fmt.Fprintf(stderr, "Serving QR codes on %s/qr\n", addr)
+	return server.ListenAndServe()
+}
+
+// handleQR generates QR code for data query parameter and writes it with format content type
+func handleQR(w http.ResponseWriter, r *http.Request, opts qrgen.Options, strict bool, timeout time.Duration) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := applyQuery(&opts, r, strict); err != nil {
+		verboseLog.Printf("Rejected %s: %v", r.URL.RequestURI(), err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Creation time in metadata is the time of the request, defaults carry a time only when it is
+	// wanted, deterministic output omits it
+	if !opts.Created.IsZero() {
+		opts.Created = time.Now()
+	}
+
+	// Generation is abandoned when client goes away or timeout expires
+	ctx, cancel := withTimeout(r.Context(), timeout)
+	defer cancel()
+
+	result, err := qrgen.GenerateContext(ctx, opts)
+	if errors.Is(err, qrgen.ErrCapacityExceeded) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		http.Error(w, "generation timed out", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		verboseLog.Printf("Failed %s: %v", r.URL.RequestURI(), err)
+		http.Error(w, "failed to generate QR code", http.StatusInternalServerError)
+		return
+	}
+	verboseLog.Printf("Served %s code of %d bytes for %s", opts.Format, len(result.Data), r.RemoteAddr)
+
+	w.Header().Set("Content-Type", qrgen.MIMEType(opts.Format))
+	w.Header().Set("Content-Length", strconv.Itoa(len(result.Data)))
+	if r.Method == http.MethodGet {
+		_, _ = result.WriteTo(w)
+	}
+}
+
+// applyQuery fills options from query parameters and validates them like command line flags
+func applyQuery(opts *qrgen.Options, r *http.Request, strict bool) error {
+	query := r.URL.Query()
+
+	opts.Content = query.Get("data")
+	if len(opts.Content) == 0 {
+		return fmt.Errorf("data parameter is required")
+	}
+	if err := checkURL(opts.Content, strict); err != nil {
+		return err
+	}
+
+	if size := query.Get("size"); len(size) > 0 {
+		value, err := strconv.Atoi(size)
+		if err != nil {
+			return fmt.Errorf("invalid size '%s'", size)
+		}
+		opts.Size = value
+	}
+
+	if level := query.Get("level"); level == qrgen.LevelAuto {
+		opts.AutoLevel = true
+	} else if len(level) > 0 {
+		value, err := qrgen.ParseLevel(level)
+		if err != nil {
+			return err
+		}
+		opts.Level, opts.AutoLevel = value, false
+	}
+
+	if format := query.Get("format"); len(format) > 0 {
+		opts.Format = format
+	}
+
+	return opts.Validate()
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mtzvd/qr-generator/qrgen"
+)
+
+// Metadata of served PNG images holds the time of each request, not the server start time
+func TestHandleQRCreationTime(t *testing.T) {
+	started := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		created time.Time
+		want    bool
+	}{
+		{"request time", started, true},
+		{"deterministic", time.Time{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := qrgen.DefaultOptions()
+			opts.Format, opts.Metadata, opts.Created = "png", true, tt.created
+
+			recorder := httptest.NewRecorder()
+			handleQR(recorder, httptest.NewRequest(http.MethodGet, "/qr?data=https://example.com", nil), opts, false, time.Minute)
+			if recorder.Code != http.StatusOK {
+				t.Fatalf("status %d: %s", recorder.Code, recorder.Body.String())
+			}
+
+			body := recorder.Body.Bytes()
+			if bytes.Contains(body, []byte(started.Format(time.RFC3339))) {
+				t.Errorf("served image holds server start time")
+			}
+			if got := bytes.Contains(body, []byte("Creation Time")); got != tt.want {
+				t.Errorf("creation time in metadata is %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+//go:build unix
+
+package main
+
+import (
+	"bytes"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+// readFIFO creates named pipe in temporary directory and returns its path with channel receiving
+// everything written into it
+func readFIFO(t *testing.T) (string, <-chan []byte) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "display")
+	if err := syscall.Mkfifo(path, 0600); err != nil {
+		t.Skipf("named pipes not supported: %v", err)
+	}
+
+	received := make(chan []byte, 1)
+	go func() {
+		// Opening for reading blocks until writer opens the pipe
+		file, err := os.Open(path)
+		if err != nil {
+			received <- nil
+			return
+		}
+		defer file.Close()
+		data, _ := io.ReadAll(file)
+		received <- data
+	}()
+	return path, received
+}
+
+func TestWriteSpecialFileFIFO(t *testing.T) {
+	path, received := readFIFO(t)
+	if !isSpecialFile(path) {
+		t.Fatalf("%s is not detected as special file", path)
+	}
+
+	want := []byte("streamed into named pipe\n")
+	if err := writeSpecialFile(path, bytes.NewReader(want)); err != nil {
+		t.Fatal(err)
+	}
+	if got := <-received; !bytes.Equal(got, want) {
+		t.Errorf("read %q from pipe, want %q", got, want)
+	}
+}
+
+func TestRunOutputFIFO(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	path, received := readFIFO(t)
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-u", "https://example.com", "-o", path, "-nodisplay"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit code %d: %s", code, stderr.String())
+	}
+
+	got := <-received
+	if !bytes.HasSuffix(got, pngTrailer) {
+		t.Fatalf("pipe got %d bytes, not a complete PNG file", len(got))
+	}
+	if _, err := png.Decode(bytes.NewReader(got)); err != nil {
+		t.Errorf("pipe got invalid PNG image: %v", err)
+	}
+	if _, err := os.Stat(path + ".png"); err == nil {
+		t.Errorf("regular file %s.png written next to pipe", path)
+	}
+}